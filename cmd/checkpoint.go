@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/theryanhowell/network-scanner/pkg/checkpoint"
+)
+
+// loadResumeState loads the --resume checkpoint file and returns the CIDR
+// and ports it was scanning, checking they match any given on the command
+// line.
+func loadResumeState(args []string) (*checkpoint.State, string, string, error) {
+	state, err := checkpoint.Load(resumeFile)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if len(state.Targets) != 1 {
+		return nil, "", "", fmt.Errorf("%s: expected a single target, found %d", resumeFile, len(state.Targets))
+	}
+
+	cidr, ports := state.Targets[0], state.Ports
+	if len(args) > 0 && args[0] != cidr {
+		return nil, "", "", fmt.Errorf("%s is a scan of %s, not %s", resumeFile, cidr, args[0])
+	}
+	if len(args) > 1 && args[1] != ports {
+		return nil, "", "", fmt.Errorf("%s is a scan of ports %s, not %s", resumeFile, ports, args[1])
+	}
+	return state, cidr, ports, nil
+}
+
+// openCheckpoint creates the --checkpoint file, or reopens the --resume file
+// to continue recording the scan in it. It returns nil if neither flag is
+// set.
+func openCheckpoint(cidr, ports string, ipList []string, portList []int, state *checkpoint.State) (*checkpoint.Checkpointer, error) {
+	var checkpointer *checkpoint.Checkpointer
+	var err error
+	switch {
+	case state != nil:
+		checkpointer, err = checkpoint.Resume(resumeFile, state, ipList, portList)
+	case checkpointFile != "":
+		checkpointer, err = checkpoint.Create(checkpointFile, []string{cidr}, ports, ipList, portList)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkpointer.SetInterval(checkpointInterval)
+	return checkpointer, nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/config"
+
+	"github.com/spf13/pflag"
+)
+
+// settingFlags are the flags that can be set in a config file: those of the
+// scan command. Other commands that read the config file ignore the
+// settings for flags they do not have.
+var settingFlags *pflag.FlagSet
+
+// applyConfig sets the flags that were not given on the command line from
+// the --profile and then from the config file. It returns the ports to
+// scan when none are given as an argument, or an empty string if neither
+// sets them.
+func applyConfig(flags *pflag.FlagSet) (string, error) {
+	cfg := &config.Config{Settings: config.Settings{}}
+	path := configFile
+	if path == "" {
+		// Without a home directory there is no default config file, but
+		// the built-in profiles can still be applied.
+		path, _ = config.DefaultPath()
+	}
+	if path != "" {
+		loaded, err := config.Load(path)
+		switch {
+		case err == nil:
+			cfg = loaded
+		case configFile != "" || !errors.Is(err, fs.ErrNotExist):
+			return "", err
+		}
+	}
+
+	name := profileName
+	if values := cfg.Settings["profile"]; !flags.Changed("profile") && len(values) > 0 {
+		name = values[len(values)-1]
+	}
+
+	var ports string
+	if name != "" {
+		profile, err := cfg.Profile(name)
+		if err != nil {
+			return "", err
+		}
+		if err := applySettings(flags, profile, &ports); err != nil {
+			return "", fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	if err := applySettings(flags, cfg.Settings, &ports); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return ports, nil
+}
+
+// applySettings sets each flag in settings that has not already been set,
+// either on the command line or by settings applied before. The ports
+// setting is stored in ports unless that is already set.
+func applySettings(flags *pflag.FlagSet, settings config.Settings, ports *string) error {
+	for _, name := range slices.Sorted(maps.Keys(settings)) {
+		values := settings[name]
+		switch name {
+		case "ports":
+			if *ports == "" {
+				*ports = strings.Join(values, ",")
+			}
+			continue
+		case "profile":
+			continue
+		case "config":
+			return errors.New("config cannot be set in a config file")
+		}
+
+		flag := flags.Lookup(name)
+		if flag == nil {
+			if settingFlags != nil && settingFlags.Lookup(name) != nil {
+				continue
+			}
+			return fmt.Errorf("unknown setting: %s", name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		if strings.HasSuffix(flag.Value.Type(), "Array") || strings.HasSuffix(flag.Value.Type(), "Slice") {
+			for _, value := range values {
+				if err := setFlag(flags, flag, value); err != nil {
+					return fmt.Errorf("invalid %s %q: %w", name, value, err)
+				}
+			}
+			continue
+		}
+		value := strings.Join(values, ",")
+		if err := setFlag(flags, flag, value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/config"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySettings(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	timeout := flags.Duration("timeout", 3*time.Second, "")
+	format := flags.String("format", "table", "")
+	rate := flags.Float64("rate", 0, "")
+	showOpen := flags.Bool("show-open", false, "")
+	outputs := flags.StringArray("output", nil, "")
+	concurrency := flags.Int("concurrency", 0, "")
+	require.NoError(t, flags.Parse([]string{"--format", "json"}))
+
+	// Flags given on the command line take precedence over the profile,
+	// which takes precedence over the config file.
+	var ports string
+	profile := config.Settings{"timeout": {"500ms"}, "format": {"csv"}, "ports": {"top100"}}
+	require.NoError(t, applySettings(flags, profile, &ports))
+	file := config.Settings{
+		"timeout":     {"2s"},
+		"rate":        {"50"},
+		"show-open":   {"true"},
+		"output":      {"json:a.json", "csv:a.csv"},
+		"ports":       {"22", "80"},
+		"profile":     {"quick"},
+		"concurrency": {"100"},
+	}
+	require.NoError(t, applySettings(flags, file, &ports))
+
+	assert.Equal(t, 500*time.Millisecond, *timeout)
+	assert.Equal(t, "json", *format)
+	assert.Equal(t, 50.0, *rate)
+	assert.True(t, *showOpen)
+	assert.Equal(t, []string{"json:a.json", "csv:a.csv"}, *outputs)
+	assert.Equal(t, 100, *concurrency)
+	assert.Equal(t, "top100", ports)
+}
+
+func TestApplySettings_Invalid(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Duration("timeout", 3*time.Second, "")
+
+	var ports string
+	err := applySettings(flags, config.Settings{"timeout": {"soon"}}, &ports)
+	assert.ErrorContains(t, err, `invalid timeout "soon"`)
+
+	err = applySettings(flags, config.Settings{"speed": {"fast"}}, &ports)
+	assert.EqualError(t, err, "unknown setting: speed")
+
+	err = applySettings(flags, config.Settings{"config": {"other.yaml"}}, &ports)
+	assert.EqualError(t, err, "config cannot be set in a config file")
+}
+
+func TestApplyConfig_NoHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	profileName = "quick"
+	t.Cleanup(func() { profileName = "" })
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	timeout := flags.Duration("timeout", 3*time.Second, "")
+
+	ports, err := applyConfig(flags)
+	require.NoError(t, err)
+	assert.Equal(t, "top100", ports)
+	assert.Equal(t, 500*time.Millisecond, *timeout)
+}
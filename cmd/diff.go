@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/theryanhowell/network-scanner/pkg/diff"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [old results] [new results]",
+	Short: "Compare the results of two scans",
+	Long: `Compare two result files written with --format json, ndjson, csv or xml and
+report new and vanished hosts, newly opened and closed ports, and changed
+services.
+
+Exits with status 0 if there are no changes, 1 if there are changes and 2 if
+the files could not be compared.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		before, err := output.ReadFile(args[0])
+		if err != nil {
+			fmt.Println("Error reading results:", err)
+			os.Exit(2)
+		}
+
+		after, err := output.ReadFile(args[1])
+		if err != nil {
+			fmt.Println("Error reading results:", err)
+			os.Exit(2)
+		}
+
+		report := diff.Compare(before, after)
+		switch diffFormat {
+		case "text":
+			err = report.WriteText(os.Stdout)
+		case "json":
+			err = report.WriteJSON(os.Stdout)
+		default:
+			err = fmt.Errorf("unknown diff format: %s", diffFormat)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+
+		if !report.Empty() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "Output format: text or json")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// discoveryPorts are the ports probed by discover when none are given.
+const discoveryPorts = "22,80,135,443,445,3389"
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover [CIDR] [ports]",
+	Short: "Find the hosts that are up",
+	Long: `Probe a few common ports on every host in a CIDR range and list the hosts
+that answered. A host is up if any port answered, even by refusing the
+connection. Each host is listed once, with its first open port, or its first
+closed port if none are open. Ports default to ` + discoveryPorts + `.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The ports setting of the config file is for scan.
+		if _, err := resolveFlags(cmd.Flags()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ports := discoveryPorts
+		if len(args) > 1 {
+			ports = args[1]
+		}
+		opts := probeOptions([]string{args[0]}, ports)
+		opts.Command = strings.Join(os.Args, " ")
+		scan, err := runner.New(opts)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Every host written is up, so closed ports are shown too.
+		if !showOpen {
+			showAll = true
+		}
+		out, err := newResultOutput(cmd, os.Stdout, true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		live := output.NewLiveHostWriter(out.Writer())
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		run, err := scan.Stream(ctx, live)
+		if err := errors.Join(err, out.Close()); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing results:", err)
+			os.Exit(1)
+		}
+		if err := out.WriteSummary(run); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing summary:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "%d of %d hosts up\n", len(live.Hosts()), len(scan.Hosts()))
+
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "Scan interrupted")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addProbeFlags(discoverCmd.Flags())
+	addOutputFlags(discoverCmd.Flags())
+	addConfigFlags(discoverCmd.Flags())
+	bindEnv(discoverCmd.Flags())
+	rootCmd.AddCommand(discoverCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+
+	"github.com/spf13/pflag"
+)
+
+// envPrefix is the prefix of the environment variables that set flags.
+const envPrefix = "NETSCAN_"
+
+// positiveFlags must be greater than zero and nonNegativeFlags must not be
+// less than zero, whichever way they are set.
+var (
+	positiveFlags    = []string{"timeout", "sort-buffer", "progress-interval", "checkpoint-interval", "interval", "max-jobs", "max-concurrency"}
+	nonNegativeFlags = []string{"banner", "concurrency", "rate", "retries", "max-queue"}
+)
+
+// valueNames describes the values of flags whose values are parsed as
+// numbers or booleans.
+var valueNames = map[string]string{
+	"bool":    "true or false",
+	"int":     "a whole number",
+	"float64": "a number",
+}
+
+// resolveFlags sets the flags that were not given on the command line from
+// their environment variables, then from the --profile and then from the
+// config file, and checks their values. It returns the ports to scan when
+// none are given as an argument, or an empty string if no setting gives
+// them.
+func resolveFlags(flags *pflag.FlagSet) (string, error) {
+	if err := checkFlags(flags); err != nil {
+		return "", err
+	}
+	if err := applyEnv(flags); err != nil {
+		return "", err
+	}
+	return applyConfig(flags)
+}
+
+// checkFlags checks the values of the flags given on the command line.
+func checkFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.Visit(func(flag *pflag.Flag) {
+		if checkErr := checkFlag(flag); checkErr != nil && err == nil {
+			err = fmt.Errorf("invalid --%s %q: %w", flag.Name, flag.Value, checkErr)
+		}
+	})
+	return err
+}
+
+// envName returns the environment variable that sets a flag, such as
+// NETSCAN_SORT_BUFFER for --sort-buffer.
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// bindEnv adds the environment variable of each flag to its help text.
+func bindEnv(flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		flag.Usage += fmt.Sprintf(" [$%s]", envName(flag.Name))
+	})
+}
+
+// applyEnv sets each flag that was not given on the command line from its
+// environment variable, if that is set and not empty. A repeatable flag is
+// given a single value.
+func applyEnv(flags *pflag.FlagSet) error {
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		name := envName(flag.Name)
+		value := os.Getenv(name)
+		if flag.Changed || value == "" {
+			return
+		}
+		if err := setFlag(flags, flag, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, value, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// setFlag sets a flag and checks its new value.
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, value string) error {
+	if err := flags.Set(flag.Name, value); err != nil {
+		// The flag and value are already part of the caller's message.
+		var numErr *strconv.NumError
+		var invalid *pflag.InvalidValueError
+		switch {
+		case errors.As(err, &numErr):
+			return fmt.Errorf("expected %s", cmp.Or(valueNames[flag.Value.Type()], flag.Value.Type()))
+		case errors.As(err, &invalid):
+			return invalid.Unwrap()
+		}
+		return err
+	}
+	return checkFlag(flag)
+}
+
+// checkFlag checks that a numeric flag is in range.
+func checkFlag(flag *pflag.Flag) error {
+	positive := slices.Contains(positiveFlags, flag.Name)
+	if !positive && !slices.Contains(nonNegativeFlags, flag.Name) {
+		return nil
+	}
+
+	var n float64
+	switch flag.Value.Type() {
+	case "duration":
+		d, err := time.ParseDuration(flag.Value.String())
+		if err != nil {
+			return err
+		}
+		n = float64(d)
+	default:
+		var err error
+		if n, err = strconv.ParseFloat(flag.Value.String(), 64); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case positive && n <= 0:
+		return errors.New("must be greater than zero")
+	case n < 0:
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+// addProbeFlags adds the flags that control how ports are probed, shared by
+// every command that scans.
+func addProbeFlags(flags *pflag.FlagSet) {
+	flags.DurationVarP(&timeout, "timeout", "t", runner.DefaultTimeout, "Timeout for each port scan")
+	flags.DurationVar(&bannerTimeout, "banner", 0, "Wait this long for a banner from each open port (disabled when 0)")
+	flags.IntVar(&concurrency, "concurrency", 0, "Maximum number of ports to scan at once (0 scans every port at once)")
+	flags.Float64Var(&rate, "rate", 0, "Maximum number of ports to start scanning each second (0 for no limit)")
+	flags.IntVar(&retries, "retries", 0, "Scan a port that timed out up to this many more times")
+}
+
+// probeOptions returns the options for a scan of targets set by the probe
+// flags.
+func probeOptions(targets []string, ports string) runner.Options {
+	return runner.Options{
+		Targets:       targets,
+		Ports:         ports,
+		Timeout:       timeout,
+		BannerTimeout: bannerTimeout,
+		Concurrency:   concurrency,
+		Rate:          rate,
+		Retries:       retries,
+	}
+}
+
+// addOutputFlags adds the flags that select how and where results are
+// written, shared by every command that writes results.
+func addOutputFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&showAll, "show-all", "a", false, "Show all ports, including closed ones")
+	flags.BoolVarP(&showOpen, "show-open", "o", false, "Only show open ports")
+	flags.BoolVarP(&csv, "csv", "c", false, "Output in CSV format (same as --format csv)")
+	flags.StringVarP(&format, "format", "f", "table", "Output format: table, pretty, csv, json, ndjson, xml, grepable, html, markdown or template")
+	flags.StringVar(&normalOutput, "oN", "", "Also write table output to a file")
+	flags.StringVar(&jsonOutput, "oJ", "", "Also write JSON output to a file")
+	flags.StringVar(&csvOutput, "oC", "", "Also write CSV output to a file")
+	flags.StringVar(&xmlOutput, "oX", "", "Also write nmap XML output to a file")
+	flags.StringVar(&grepableOutput, "oG", "", "Also write grepable output to a file")
+	flags.StringArrayVar(&extraOutputs, "output", nil, "Also write to a file, as FORMAT:FILE[:STATUSES], e.g. json:open.json:open (repeatable)")
+	flags.StringVar(&sortKeys, "sort", "", "Sort results by comma-separated keys: host, port, status, latency (prefix with - to reverse)")
+	flags.IntVar(&sortBuffer, "sort-buffer", output.DefaultSortBufferSize, "Results to sort in memory before spilling to temporary files")
+	flags.BoolVar(&bufferTable, "buffer", false, "Print the table once the scan finishes, with columns sized to fit the results")
+	flags.BoolVar(&wrapTable, "wrap", false, "Wrap long table cells instead of shortening them")
+	flags.StringVar(&color, "color", "auto", "Color pretty output: auto, always or never")
+	flags.BoolVar(&groupHosts, "group-hosts", false, "Group markdown output under a heading per host")
+	flags.StringVar(&templateText, "template", "", "Go text/template executed for each result (implies --format template)")
+	flags.StringVar(&templateFile, "template-file", "", "Read the --template from a file")
+	flags.BoolVar(&showSummary, "summary", true, "Print summary statistics after table and pretty output")
+}
+
+// addConfigFlags adds the flags that select the config file and profile.
+func addConfigFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&configFile, "config", "", "Read default flag values and profiles from this YAML file (default $XDG_CONFIG_HOME/network-scanner/config.yaml)")
+	flags.StringVar(&profileName, "profile", "", "Apply a named profile from the config file, or a built-in one: quick, full or prod-safe")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/config"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "NETSCAN_TIMEOUT", envName("timeout"))
+	assert.Equal(t, "NETSCAN_SORT_BUFFER", envName("sort-buffer"))
+	assert.Equal(t, "NETSCAN_OJ", envName("oJ"))
+}
+
+func TestBindEnv(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Float64("rate", 0, "Maximum rate")
+	bindEnv(flags)
+
+	assert.Equal(t, "Maximum rate [$NETSCAN_RATE]", flags.Lookup("rate").Usage)
+}
+
+// TestPrecedence checks that a flag given on the command line takes
+// precedence over its environment variable, which takes precedence over the
+// profile, which takes precedence over the rest of the config file.
+func TestPrecedence(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	timeout := flags.Duration("timeout", 3*time.Second, "")
+	rate := flags.Float64("rate", 0, "")
+	retries := flags.Int("retries", 0, "")
+	concurrency := flags.Int("concurrency", 0, "")
+	showAll := flags.Bool("show-all", false, "")
+	format := flags.String("format", "table", "")
+	require.NoError(t, flags.Parse([]string{"--timeout", "1s"}))
+
+	t.Setenv("NETSCAN_TIMEOUT", "2s")
+	t.Setenv("NETSCAN_RATE", "20")
+	t.Setenv("NETSCAN_SHOW_ALL", "true")
+	t.Setenv("NETSCAN_FORMAT", "")
+	require.NoError(t, applyEnv(flags))
+
+	var ports string
+	profile := config.Settings{"timeout": {"500ms"}, "rate": {"50"}, "retries": {"2"}}
+	require.NoError(t, applySettings(flags, profile, &ports))
+	file := config.Settings{"rate": {"100"}, "retries": {"3"}, "concurrency": {"10"}}
+	require.NoError(t, applySettings(flags, file, &ports))
+
+	assert.Equal(t, time.Second, *timeout)
+	assert.Equal(t, 20.0, *rate)
+	assert.Equal(t, 2, *retries)
+	assert.Equal(t, 10, *concurrency)
+	assert.True(t, *showAll)
+	// An empty variable is ignored.
+	assert.Equal(t, "table", *format)
+}
+
+func TestApplyEnv_Invalid(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Duration("timeout", 3*time.Second, "")
+	flags.Float64("rate", 0, "")
+	flags.Int("concurrency", 0, "")
+	flags.Bool("show-all", false, "")
+
+	tests := []struct {
+		name, value, err string
+	}{
+		{"NETSCAN_TIMEOUT", "soon", `invalid NETSCAN_TIMEOUT "soon": time: invalid duration "soon"`},
+		{"NETSCAN_TIMEOUT", "0s", `invalid NETSCAN_TIMEOUT "0s": must be greater than zero`},
+		{"NETSCAN_RATE", "-5", `invalid NETSCAN_RATE "-5": must not be negative`},
+		{"NETSCAN_CONCURRENCY", "many", `invalid NETSCAN_CONCURRENCY "many": expected a whole number`},
+		{"NETSCAN_SHOW_ALL", "maybe", `invalid NETSCAN_SHOW_ALL "maybe": expected true or false`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			assert.EqualError(t, applyEnv(flags), tt.err)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/history"
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyDB     string
+	historyFormat string
+
+	queryHosts    []string
+	queryPorts    string
+	queryOpen     bool
+	queryFlapping bool
+	querySince    time.Duration
+	queryFormat   string
+)
+
+// historyTimeFormat is how times are shown by the history and query
+// commands.
+const historyTimeFormat = "2006-01-02 15:04"
+
+var historyCmd = &cobra.Command{
+	Use:   "history [run ID]",
+	Short: "List recorded scans or show the results of one",
+	Long: `List the scans recorded with --history, or print the results of a single
+recorded scan in any output format.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := history.Open(historyDB)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		if len(args) == 0 {
+			err = listRuns(store)
+		} else {
+			err = showRun(store, args[0])
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// listRuns prints a line for every recorded run.
+func listRuns(store *history.Store) error {
+	runs, err := store.Runs()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tStarted\tElapsed\tTargets\tPorts\tHosts\tOpen\tClosed\tTimed Out")
+	for _, run := range runs {
+		elapsed := "unfinished"
+		if !run.End.IsZero() {
+			elapsed = run.End.Sub(run.Start).Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			run.ID, run.Start.Local().Format(historyTimeFormat), elapsed,
+			strings.Join(run.Targets, ","), run.Ports,
+			run.Hosts, run.Open, run.Closed, run.Timeout)
+	}
+	return w.Flush()
+}
+
+// showRun writes the results of a recorded run in the --format format.
+func showRun(store *history.Store, arg string) error {
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid run ID: %s", arg)
+	}
+
+	run, err := store.Run(id)
+	if err != nil {
+		return err
+	}
+	results, err := store.Results(id)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newWriter(historyFormat, os.Stdout, terminalWidth(os.Stdout), false)
+	if err != nil {
+		return err
+	}
+
+	scanRun := &output.ScanRun{
+		Command: run.Command,
+		Targets: run.Targets,
+		Ports:   run.Ports,
+		Start:   run.Start,
+		End:     run.End,
+	}
+	if err := writer.Begin(scanRun); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := writer.WriteResult(r); err != nil {
+			return err
+		}
+	}
+	return writer.End(scanRun)
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Find ports in the scan history",
+	Long: `Find ports that have ever been open in the scans recorded with --history,
+with when they were first and last seen open and how often they flapped
+between open and not open.
+
+For example, every host where RDP was ever open:
+
+  network-scanner query --db history.db --ports 3389`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		query, err := buildQuery()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		store, err := history.Open(historyDB)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		histories, err := store.Ports(query)
+		if err == nil {
+			err = writeHistories(histories)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// buildQuery builds a history query from the query command's flags.
+func buildQuery() (history.Query, error) {
+	query := history.Query{Open: queryOpen, Flapping: queryFlapping}
+
+	for _, h := range queryHosts {
+		prefix, err := iputil.ParsePrefix(h)
+		if err != nil {
+			return query, err
+		}
+		query.Hosts = append(query.Hosts, prefix)
+	}
+
+	if queryPorts != "" {
+		ports, err := iputil.ParsePorts(queryPorts)
+		if err != nil {
+			return query, fmt.Errorf("error parsing ports: %w", err)
+		}
+		query.Ports = ports
+	}
+
+	if querySince > 0 {
+		query.Since = time.Now().Add(-querySince)
+	}
+	return query, nil
+}
+
+// writeHistories prints port histories in the --format format.
+func writeHistories(histories []history.PortHistory) error {
+	switch queryFormat {
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Host\tPort\tService\tFirst Open\tLast Open\tLast Status\tOpen\tFlaps")
+		for _, h := range histories {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%d/%d\t%d\n",
+				h.Host, h.Port, h.Service,
+				h.FirstOpen.Local().Format(historyTimeFormat),
+				h.LastOpen.Local().Format(historyTimeFormat),
+				h.LastStatus, h.OpenCount, h.Scans, h.Flaps)
+		}
+		return w.Flush()
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(histories)
+	default:
+		return fmt.Errorf("unknown query format: %s", queryFormat)
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{historyCmd, queryCmd} {
+		cmd.Flags().StringVar(&historyDB, "db", "", "History database written by --history")
+		cmd.MarkFlagRequired("db")
+	}
+
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "table", "Output format for the results of a run, as for a scan")
+
+	queryCmd.Flags().StringArrayVar(&queryHosts, "host", nil, "Only show hosts in this address or CIDR range (repeatable)")
+	queryCmd.Flags().StringVarP(&queryPorts, "ports", "p", "", "Only show these ports, as a list or range")
+	queryCmd.Flags().BoolVar(&queryOpen, "open", false, "Only show ports that were open in their latest scan")
+	queryCmd.Flags().BoolVar(&queryFlapping, "flapping", false, "Only show ports that have changed between open and not open")
+	queryCmd.Flags().DurationVar(&querySince, "since", 0, "Only show ports seen open within this long, e.g. 168h")
+	queryCmd.Flags().StringVarP(&queryFormat, "format", "f", "table", "Output format: table or json")
+
+	rootCmd.AddCommand(historyCmd, queryCmd)
+}
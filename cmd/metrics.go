@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newMetrics creates a registry holding the scanner's metrics. Long-running
+// commands also report the Go runtime's and the process's metrics, such as
+// the number of goroutines.
+func newMetrics(runtime bool) (*prometheus.Registry, *scanner.Metrics) {
+	reg := prometheus.NewRegistry()
+	if runtime {
+		reg.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+	return reg, scanner.NewMetrics(reg)
+}
+
+// metricsHandler serves the metrics in reg in the Prometheus exposition
+// format.
+func metricsHandler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// serveMetrics serves the metrics in reg on /metrics at addr in the
+// background. It fails if addr cannot be listened on.
+func serveMetrics(addr string, reg *prometheus.Registry) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler(reg))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	return server, nil
+}
+
+// writeMetricsFile writes the metrics in reg to a file for the node
+// exporter's textfile collector. The file is replaced atomically.
+func writeMetricsFile(path string, reg *prometheus.Registry) error {
+	if path == "" {
+		return nil
+	}
+	return prometheus.WriteToTextfile(path, reg)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeMetrics_AddressInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	reg, _ := newMetrics(false)
+	_, err = serveMetrics(listener.Addr().String(), reg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/theryanhowell/network-scanner/pkg/notify"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+)
+
+// loadDispatcher loads the --notify config file. It returns nil if the flag
+// is not set.
+func loadDispatcher() (*notify.Dispatcher, error) {
+	if notifyFile == "" {
+		return nil, nil
+	}
+
+	cfg, err := notify.Load(notifyFile)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewDispatcher(cfg)
+}
+
+// scanNotifier sends a scan's notifications when it ends, until ctx is
+// done. A failed notification is reported on stderr rather than failing
+// the scan, whose results have been written regardless.
+type scanNotifier struct {
+	*notify.Dispatcher
+	ctx context.Context
+}
+
+// End sends the scan's findings.
+func (n scanNotifier) End(run *output.ScanRun) error {
+	if err := n.NotifyScan(n.ctx, run); err != nil {
+		fmt.Fprintln(os.Stderr, "Error sending notifications:", err)
+	}
+	return nil
+}
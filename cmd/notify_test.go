@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theryanhowell/network-scanner/pkg/notify"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanNotifier_ErrorsAreNotFatal(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	retries := 3
+	d, err := notify.NewDispatcher(&notify.Config{
+		Notifiers: []notify.NotifierConfig{{URL: ts.URL, Retries: &retries}},
+		Rules:     []notify.Rule{{}},
+	})
+	require.NoError(t, err)
+
+	// Once the scan's context is done, failed notifications are not retried.
+	ctx, cancel := context.WithCancel(context.Background())
+	n := scanNotifier{Dispatcher: d, ctx: ctx}
+	run := &output.ScanRun{Targets: []string{"10.0.0.0/24"}}
+	require.NoError(t, n.Begin(run))
+	require.NoError(t, n.WriteResult(output.Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open}))
+	cancel()
+
+	assert.NoError(t, n.End(run))
+	assert.LessOrEqual(t, requests, 1)
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// showBanners adds a banner column to table output even when banners are
+// not being read, for results that already have them.
+var showBanners bool
+
+// newWriter creates the output writer for a format name. width is the width
+// of the terminal w writes to, or zero if it is not a terminal. live enables
+// the live status line of the pretty format.
+func newWriter(format string, w io.Writer, width int, live bool) (output.OutputWriter, error) {
+	switch format {
+	case "table":
+		tableWriter := output.NewTableWriter(w)
+		tableWriter.SetShowBanner(bannerTimeout > 0 || showBanners)
+		tableWriter.SetBuffered(bufferTable)
+		tableWriter.SetWrap(wrapTable)
+		tableWriter.SetMaxWidth(width)
+		return tableWriter, nil
+	case "csv":
+		return output.NewCsvWriter(w), nil
+	case "json":
+		return output.NewJsonWriter(w), nil
+	case "ndjson":
+		return output.NewNdjsonWriter(w), nil
+	case "xml":
+		return output.NewXmlWriter(w), nil
+	case "grepable":
+		return output.NewGrepableWriter(w), nil
+	case "html":
+		return output.NewHtmlWriter(w), nil
+	case "markdown":
+		markdownWriter := output.NewMarkdownWriter(w)
+		markdownWriter.SetGroupByHost(groupHosts)
+		return markdownWriter, nil
+	case "pretty":
+		prettyWriter := output.NewPrettyWriter(w)
+		prettyWriter.SetLive(live)
+		switch color {
+		case "always":
+			prettyWriter.SetColor(true)
+		case "auto":
+			prettyWriter.SetColor(width > 0 && os.Getenv("NO_COLOR") == "")
+		case "never":
+		default:
+			return nil, fmt.Errorf("invalid --color value: %s", color)
+		}
+		return prettyWriter, nil
+	case "template":
+		text := templateText
+		if templateFile != "" {
+			b, err := os.ReadFile(templateFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading template file: %w", err)
+			}
+			text = string(b)
+		}
+		if text == "" {
+			return nil, fmt.Errorf("--format template requires --template or --template-file")
+		}
+		return output.NewTemplateWriter(w, text)
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// resultOutput writes results to stdout in the --format and to the files
+// requested by the output flags, sorted if --sort is set. Writers added with
+// Add are given every result as it arrives, in front of any sorting, so
+// that checkpoints, history and the like are not held back until the end.
+type resultOutput struct {
+	all    *output.MultiWriter
+	sorter *output.Sorter
+	files  []*fileOutput
+	stdout io.Writer
+}
+
+// newResultOutput creates the output selected by the output flags. stdout
+// is written to in place of os.Stdout, and live enables the live status line
+// of the pretty format.
+func newResultOutput(cmd *cobra.Command, stdout io.Writer, live bool) (*resultOutput, error) {
+	if csv {
+		format = "csv"
+	}
+	if templateText != "" || templateFile != "" {
+		if format != "template" && (csv || cmd.Flags().Changed("format")) {
+			return nil, fmt.Errorf("--template and --template-file cannot be used with --format %s", format)
+		}
+		format = "template"
+	}
+
+	var compare output.CompareFunc
+	if sortKeys != "" {
+		var err error
+		if compare, err = output.ParseSortKeys(sortKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	width := terminalWidth(os.Stdout)
+	stdoutWriter, err := newWriter(format, stdout, width, width > 0 && live)
+	if err != nil {
+		return nil, err
+	}
+
+	display := output.NewMultiWriter()
+	display.Add(stdoutWriter, defaultFilter())
+	files, err := addFileOutputs(display)
+	if err != nil {
+		return nil, err
+	}
+
+	var sorted output.OutputWriter = display
+	var sorter *output.Sorter
+	if compare != nil {
+		sorter = output.NewSorter(display, compare)
+		sorter.SetBufferSize(sortBuffer)
+		sorted = sorter
+	}
+
+	all := output.NewMultiWriter()
+	all.Add(sorted, nil)
+	return &resultOutput{all: all, sorter: sorter, files: files, stdout: stdout}, nil
+}
+
+// Add adds a writer that is given every result, unfiltered and unsorted.
+func (o *resultOutput) Add(writer output.OutputWriter) {
+	o.all.Add(writer, nil)
+}
+
+// Writer returns the writer to stream results to.
+func (o *resultOutput) Writer() output.OutputWriter {
+	return o.all
+}
+
+// Close flushes and closes the output files, and deletes any temporary
+// files left by sorting.
+func (o *resultOutput) Close() error {
+	var err error
+	if o.sorter != nil {
+		err = o.sorter.Close()
+	}
+	return errors.Join(err, closeFileOutputs(o.files))
+}
+
+// WriteSummary prints the run's summary statistics after table and pretty
+// output, unless --summary=false.
+func (o *resultOutput) WriteSummary(run *output.ScanRun) error {
+	if !showSummary || (format != "table" && format != "pretty") {
+		return nil
+	}
+	fmt.Fprintln(o.stdout)
+	return run.Summary.Write(o.stdout)
+}
+
+// terminalWidth returns the width of f if it is a terminal, or zero.
+func terminalWidth(f *os.File) int {
+	if !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// fileOutput is an output file and the buffer in front of it.
+type fileOutput struct {
+	file   *os.File
+	buffer *bufio.Writer
+}
+
+// addFileOutputs creates the files requested by the -oN, -oJ, -oC, -oX, -oG
+// and --output flags and adds a writer for each to multi. If any file cannot
+// be created, those already created are closed and the error is returned.
+func addFileOutputs(multi *output.MultiWriter) ([]*fileOutput, error) {
+	type request struct {
+		format, path, statuses string
+	}
+
+	var requests []request
+	for _, r := range []request{
+		{"table", normalOutput, ""},
+		{"json", jsonOutput, ""},
+		{"csv", csvOutput, ""},
+		{"xml", xmlOutput, ""},
+		{"grepable", grepableOutput, ""},
+	} {
+		if r.path != "" {
+			requests = append(requests, r)
+		}
+	}
+	for _, spec := range extraOutputs {
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --output %q: expected FORMAT:FILE[:STATUSES]", spec)
+		}
+		r := request{format: parts[0], path: parts[1]}
+		if len(parts) == 3 {
+			r.statuses = parts[2]
+		}
+		requests = append(requests, r)
+	}
+
+	var files []*fileOutput
+	for _, r := range requests {
+		filter := defaultFilter()
+		if r.statuses != "" {
+			var err error
+			if filter, err = output.ParseStatusFilter(r.statuses); err != nil {
+				return nil, errors.Join(err, closeFileOutputs(files))
+			}
+		}
+
+		f, err := os.Create(r.path)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("creating output file: %w", err), closeFileOutputs(files))
+		}
+		out := &fileOutput{file: f, buffer: bufio.NewWriter(f)}
+		files = append(files, out)
+
+		writer, err := newWriter(r.format, out.buffer, 0, false)
+		if err != nil {
+			return nil, errors.Join(err, closeFileOutputs(files))
+		}
+		multi.Add(writer, filter)
+	}
+
+	return files, nil
+}
+
+// closeFileOutputs flushes and closes every output file.
+func closeFileOutputs(files []*fileOutput) error {
+	var errs []error
+	for _, f := range files {
+		if err := f.buffer.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("writing %s: %w", f.file.Name(), err))
+		}
+		if err := f.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing %s: %w", f.file.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// nmapOutputFlag matches nmap style output flags such as -oN and -oJ=file.
+var nmapOutputFlag = regexp.MustCompile(`^-o[NJCXG](=|$)`)
+
+// normalizeOutputFlags rewrites nmap style -oN, -oJ, -oC, -oX and -oG
+// arguments to the long flags they stand for, since single dash flags can
+// only have one letter.
+func normalizeOutputFlags(args []string) []string {
+	normalized := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			copy(normalized[i:], args[i:])
+			break
+		}
+		if nmapOutputFlag.MatchString(arg) {
+			arg = "-" + arg
+		}
+		normalized[i] = arg
+	}
+	return normalized
+}
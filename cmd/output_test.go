@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOutputFlags(t *testing.T) {
+	args := []string{"10.0.0.0/24", "-oN", "scan.txt", "-oJ=scan.json", "-o", "-oa", "--oC", "scan.csv", "--", "-oX"}
+
+	expected := []string{"10.0.0.0/24", "--oN", "scan.txt", "--oJ=scan.json", "-o", "-oa", "--oC", "scan.csv", "--", "-oX"}
+	assert.Equal(t, expected, normalizeOutputFlags(args))
+}
+
+// resultCounter is an OutputWriter that counts results.
+type resultCounter struct {
+	count int
+}
+
+func (c *resultCounter) Begin(run *output.ScanRun) error { return nil }
+
+func (c *resultCounter) WriteResult(r output.Result) error {
+	c.count++
+	return nil
+}
+
+func (c *resultCounter) End(run *output.ScanRun) error { return nil }
+
+func TestResultOutput_AddedWritersAreNotSorted(t *testing.T) {
+	sortKeys, format = "port", "csv"
+	t.Cleanup(func() { sortKeys, format = "", "table" })
+
+	var stdout bytes.Buffer
+	out, err := newResultOutput(&cobra.Command{}, &stdout, false)
+	require.NoError(t, err)
+	counter := &resultCounter{}
+	out.Add(counter)
+
+	run := &output.ScanRun{}
+	writer := out.Writer()
+	require.NoError(t, writer.Begin(run))
+	require.NoError(t, writer.WriteResult(output.Result{Host: "10.0.0.1", Port: 80, Status: scanner.Open}))
+	require.NoError(t, writer.WriteResult(output.Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open}))
+
+	// The sorted output waits for the end of the scan; the added writer
+	// does not.
+	assert.Equal(t, 2, counter.count)
+	assert.Equal(t, "IP Address,Port,Status,Latency (ms),Banner\n", stdout.String())
+
+	require.NoError(t, writer.End(run))
+	assert.Equal(t, "IP Address,Port,Status,Latency (ms),Banner\n10.0.0.1,22,Open,0.000,\n10.0.0.1,80,Open,0.000,\n", stdout.String())
+}
+
+func TestResultOutput_TemplateWithOtherFormat(t *testing.T) {
+	cmd := &cobra.Command{}
+	addOutputFlags(cmd.Flags())
+	templateText = "{{.Host}}\n"
+	t.Cleanup(func() { templateText, format = "", "table" })
+	require.NoError(t, cmd.Flags().Set("format", "json"))
+	_, err := newResultOutput(cmd, &bytes.Buffer{}, false)
+	assert.EqualError(t, err, "--template and --template-file cannot be used with --format json")
+
+	require.NoError(t, cmd.Flags().Set("format", "template"))
+	_, err = newResultOutput(cmd, &bytes.Buffer{}, false)
+	assert.NoError(t, err)
+}
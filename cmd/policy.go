@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/theryanhowell/network-scanner/pkg/policy"
+)
+
+// reportViolations prints policy violations to w and, if --junit is set,
+// writes them as a JUnit XML report.
+func reportViolations(w io.Writer, checker *policy.Checker) ([]policy.Violation, error) {
+	violations := checker.Violations()
+	for _, v := range violations {
+		fmt.Fprintln(w, "Policy violation:", v)
+	}
+
+	if junitOutput == "" {
+		return violations, nil
+	}
+
+	f, err := os.Create(junitOutput)
+	if err != nil {
+		return violations, err
+	}
+	if err := policy.WriteJUnit(f, checker.Hosts(), violations); err != nil {
+		f.Close()
+		return violations, err
+	}
+	return violations, f.Close()
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [results file]...",
+	Short: "Write saved results in another format",
+	Long: `Read results written with --format json, ndjson, csv or xml and write them
+again with the output flags, for example as an HTML report or sorted and
+filtered. The results of several files are combined.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := resolveFlags(cmd.Flags()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		var results []output.Result
+		for _, path := range args {
+			r, err := output.ReadFile(path)
+			if err != nil {
+				fmt.Println("Error reading results:", err)
+				os.Exit(1)
+			}
+			results = append(results, r...)
+		}
+		showBanners = slices.ContainsFunc(results, func(r output.Result) bool {
+			return r.Banner != ""
+		})
+
+		out, err := newResultOutput(cmd, os.Stdout, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		hosts := make(map[string]bool)
+		for _, r := range results {
+			hosts[r.Host] = true
+		}
+		ports := make(chan scanner.Port)
+		go func() {
+			defer close(ports)
+			for _, r := range results {
+				ports <- scanner.Port{Host: r.Host, Port: r.Port, Status: r.Status, Latency: r.Latency, Banner: r.Banner}
+			}
+		}()
+
+		// The files do not record what was scanned, only the results.
+		run := &output.ScanRun{
+			Command:   strings.Join(os.Args, " "),
+			Targets:   []string{},
+			HostCount: len(hosts),
+			Start:     time.Now(),
+		}
+		err = output.Stream(out.Writer(), run, ports, nil)
+		if err := errors.Join(err, out.Close()); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing results:", err)
+			os.Exit(1)
+		}
+		if err := out.WriteSummary(run); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing summary:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addOutputFlags(reportCmd.Flags())
+	addConfigFlags(reportCmd.Flags())
+	bindEnv(reportCmd.Flags())
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/checkpoint"
+	"github.com/theryanhowell/network-scanner/pkg/history"
+	"github.com/theryanhowell/network-scanner/pkg/policy"
+	"github.com/theryanhowell/network-scanner/pkg/progress"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	showProgress     bool
+	progressInterval time.Duration
+
+	policyFile  string
+	junitOutput string
+	historyFile string
+	notifyFile  string
+	metricsFile string
+
+	checkpointFile     string
+	checkpointInterval time.Duration
+	resumeFile         string
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [CIDR] [ports]",
+	Short: "Scan a network for open ports",
+	Long: `Scan every host in a CIDR range for open ports and write the results in any
+of the output formats. Ports default to 1-1024.
+
+"network-scanner scan CIDR" can be shortened to "network-scanner CIDR".`,
+	Args: scanArgs,
+	Run:  runScan,
+}
+
+// scanArgs requires a CIDR unless the scan is resumed from a checkpoint,
+// which records it.
+func scanArgs(cmd *cobra.Command, args []string) error {
+	if resumeFile != "" {
+		return cobra.MaximumNArgs(2)(cmd, args)
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// runScan runs the scan command.
+func runScan(cmd *cobra.Command, args []string) {
+	configPorts, err := resolveFlags(cmd.Flags())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var state *checkpoint.State
+	var cidr string
+	ports := configPorts
+	if resumeFile != "" {
+		if checkpointFile != "" {
+			fmt.Println("--checkpoint and --resume cannot be used together; --resume keeps writing to its checkpoint file")
+			os.Exit(1)
+		}
+		state, cidr, ports, err = loadResumeState(args)
+		if err != nil {
+			fmt.Println("Error loading checkpoint:", err)
+			os.Exit(1)
+		}
+	} else {
+		cidr = args[0]
+		if len(args) > 1 {
+			ports = args[1]
+		}
+	}
+
+	reg, metrics := newMetrics(false)
+	opts := probeOptions([]string{cidr}, ports)
+	opts.Resume = state
+	if (checkpointFile != "" || state != nil) && opts.Concurrency == 0 && opts.Rate == 0 {
+		opts.Concurrency = checkpoint.DefaultConcurrency
+	}
+	opts.Command = strings.Join(os.Args, " ")
+	if metricsFile != "" {
+		opts.Metrics = metrics
+	}
+	scan, err := runner.New(opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var stdout io.Writer = os.Stdout
+	var reporter *progress.Reporter
+	if showProgress {
+		reporter = progress.NewReporter(os.Stderr, scan.Progress())
+		reporter.SetInteractive(terminalWidth(os.Stderr) > 0)
+		reporter.SetInterval(progressInterval)
+		stdout = reporter.Wrap(os.Stdout)
+	}
+
+	var checker *policy.Checker
+	if policyFile != "" {
+		p, err := policy.Load(policyFile)
+		if err != nil {
+			fmt.Println("Error loading policy:", err)
+			os.Exit(1)
+		}
+		checker = policy.NewChecker(p)
+	} else if junitOutput != "" {
+		fmt.Println("--junit requires --policy")
+		os.Exit(1)
+	}
+
+	out, err := newResultOutput(cmd, stdout, reporter == nil)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if checker != nil {
+		out.Add(checker)
+	}
+	if historyFile != "" {
+		store, err := history.Open(historyFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer store.Close()
+		out.Add(history.NewRecorder(store))
+	}
+
+	// Stop starting new probes on the first interrupt, so that the results
+	// so far are still written. A second interrupt exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	dispatcher, err := loadDispatcher()
+	if err != nil {
+		fmt.Println("Error loading notifications:", err)
+		os.Exit(1)
+	}
+	if dispatcher != nil {
+		out.Add(scanNotifier{Dispatcher: dispatcher, ctx: ctx})
+	}
+	checkpointer, err := openCheckpoint(cidr, opts.Ports, scan.Hosts(), scan.Ports(), state)
+	if err != nil {
+		fmt.Println("Error opening checkpoint:", err)
+		os.Exit(1)
+	}
+	if checkpointer != nil {
+		out.Add(checkpointer)
+	}
+
+	if reporter != nil {
+		reporter.Start()
+	}
+	run, err := scan.Stream(ctx, out.Writer())
+	if reporter != nil {
+		reporter.Stop()
+	}
+	err = errors.Join(err, out.Close(), writeMetricsFile(metricsFile, reg))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing results:", err)
+		os.Exit(1)
+	}
+
+	if err := out.WriteSummary(run); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing summary:", err)
+		os.Exit(1)
+	}
+
+	if checker != nil {
+		violations, err := reportViolations(os.Stderr, checker)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing JUnit report:", err)
+			os.Exit(1)
+		}
+		if len(violations) > 0 {
+			fmt.Fprintf(os.Stderr, "%d policy violation(s)\n", len(violations))
+			os.Exit(1)
+		}
+	}
+
+	if ctx.Err() != nil && (checkpointer == nil || !checkpointer.Complete()) {
+		fmt.Fprintln(os.Stderr, "Scan interrupted")
+		if checkpointer != nil {
+			fmt.Fprintf(os.Stderr, "Continue it with --resume %s\n", cmp.Or(resumeFile, checkpointFile))
+		}
+		os.Exit(1)
+	}
+}
+
+// addScanFlags adds the flags of the scan command to flags.
+func addScanFlags(flags *pflag.FlagSet) {
+	addProbeFlags(flags)
+	addOutputFlags(flags)
+	flags.BoolVar(&showProgress, "progress", true, "Report progress on stderr: a progress bar on a terminal, otherwise a periodic status line")
+	flags.DurationVar(&progressInterval, "progress-interval", progress.DefaultInterval, "How often to print a status line when stderr is not a terminal")
+	flags.StringVar(&policyFile, "policy", "", "Check results against a YAML or JSON policy file and exit nonzero on violations")
+	flags.StringVar(&junitOutput, "junit", "", "Write policy violations to a file as a JUnit XML report")
+	flags.StringVar(&notifyFile, "notify", "", "Send notifications about results that match the rules in a YAML or JSON file")
+	flags.StringVar(&metricsFile, "metrics-file", "", "Write Prometheus metrics to this file, for the node exporter's textfile collector")
+	flags.StringVar(&historyFile, "history", "", "Record the scan and all of its results in a history database")
+	flags.StringVar(&checkpointFile, "checkpoint", "", "Periodically save the scan's progress to a file so it can be resumed; implies --concurrency 1000 unless --concurrency or --rate is set")
+	flags.DurationVar(&checkpointInterval, "checkpoint-interval", checkpoint.DefaultInterval, "How often to save progress to the --checkpoint file")
+	flags.StringVar(&resumeFile, "resume", "", "Continue a scan from its checkpoint file, skipping ports already scanned")
+	addConfigFlags(flags)
+}
+
+func init() {
+	addScanFlags(scanCmd.Flags())
+	bindEnv(scanCmd.Flags())
+	settingFlags = scanCmd.Flags()
+	rootCmd.AddCommand(scanCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/server"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen    string
+	serveMaxJobs   int
+	serveMaxQueue  int
+	serveMaxProbes int
+	serveMaxConc   int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run scans requested over an HTTP API",
+	Long: `Serve a REST API for submitting scan jobs, polling their status, streaming
+their results as Server-Sent Events and cancelling them.
+
+  POST   /jobs              submit a job: {"targets": ["10.0.0.0/24"], "ports": "22,80"}
+  GET    /jobs              list jobs
+  GET    /jobs/{id}         get a job's status and progress
+  GET    /jobs/{id}/results get a job's results so far
+  GET    /jobs/{id}/events  stream a job's results as Server-Sent Events
+  DELETE /jobs/{id}         cancel a job
+  GET    /metrics           Prometheus metrics
+
+The API has no authentication, so it listens on localhost by default.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkFlags(cmd.Flags()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		reg, metrics := newMetrics(true)
+		api := server.New(serveMaxJobs, serveMaxQueue)
+		api.SetMaxProbes(serveMaxProbes)
+		api.SetMaxConcurrency(serveMaxConc)
+		api.SetMetrics(metrics)
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metricsHandler(reg))
+		mux.Handle("/", api)
+		httpServer := &http.Server{
+			Addr:              serveListen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- httpServer.ListenAndServe()
+		}()
+		fmt.Fprintln(os.Stderr, "Listening on", serveListen)
+
+		select {
+		case err := <-errs:
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		case <-ctx.Done():
+		}
+
+		// Cancelling the jobs first ends their event streams, so that
+		// Shutdown does not wait for them.
+		fmt.Fprintln(os.Stderr, "Shutting down")
+		api.Close()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().IntVar(&serveMaxJobs, "max-jobs", server.DefaultMaxRunning, "Maximum number of jobs to run at once")
+	serveCmd.Flags().IntVar(&serveMaxQueue, "max-queue", server.DefaultMaxQueued, "Maximum number of jobs waiting to run; more are rejected with 429")
+	serveCmd.Flags().IntVar(&serveMaxProbes, "max-probes", server.DefaultMaxProbes, "Maximum number of ports a job may scan, counting each port on each host")
+	serveCmd.Flags().IntVar(&serveMaxConc, "max-concurrency", server.DefaultMaxConcurrency, "Maximum number of ports a job may scan at once; jobs asking for more are rejected")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchCron     string
+	watchState    string
+	eventFormat   string
+	eventOutputs  []string
+
+	metricsListen string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [CIDR] [ports]",
+	Short: "Rescan on a schedule and report what changed",
+	Long: `Scan a network on an interval or cron schedule and print an event for every
+change since the previous scan: hosts that appeared or vanished, and ports
+that opened, closed or changed service.
+
+The first scan sets the baseline. With --state, the latest results are kept in
+a file and a restarted watch compares with them instead. Scans never overlap,
+and an interrupted scan is discarded.
+
+Events are printed to stdout, appended to every --event-output file and sent
+by --notify.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ports, err := resolveFlags(cmd.Flags())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(args) > 1 {
+			ports = args[1]
+		}
+
+		opts := probeOptions([]string{args[0]}, ports)
+		if _, err := runner.New(opts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		schedule := watch.Every(watchInterval)
+		if watchCron != "" {
+			if cmd.Flags().Changed("interval") {
+				fmt.Println("--interval and --cron cannot be used together")
+				os.Exit(1)
+			}
+			schedule, err = watch.ParseCron(watchCron)
+			if err != nil {
+				fmt.Println("Error parsing cron expression:", err)
+				os.Exit(1)
+			}
+		}
+
+		printEvent, err := eventPrinter(eventFormat, os.Stdout)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		printers, files, err := openEventOutputs(eventOutputs)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer closeEventOutputs(files)
+		printers = append([]func(watch.Event) error{printEvent}, printers...)
+
+		dispatcher, err := loadDispatcher()
+		if err != nil {
+			fmt.Println("Error loading notifications:", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		reg, metrics := newMetrics(true)
+		if metricsListen != "" {
+			metricsServer, err := serveMetrics(metricsListen, reg)
+			if err != nil {
+				fmt.Println("Error serving metrics:", err)
+				os.Exit(1)
+			}
+			defer metricsServer.Close()
+		}
+
+		opts.Metrics = metrics
+		scan := func(ctx context.Context) ([]output.Result, error) {
+			s, err := runner.New(opts)
+			if err != nil {
+				return nil, err
+			}
+			return s.Collect(ctx)
+		}
+
+		watcher := watch.NewWatcher(scan, schedule, func(c watch.Cycle) error {
+			for _, e := range c.Events {
+				for _, write := range printers {
+					if err := write(e); err != nil {
+						return err
+					}
+				}
+			}
+			if dispatcher != nil {
+				// A failed notification should not stop the watch.
+				if err := dispatcher.NotifyEvents(ctx, []string{args[0]}, c.Events); err != nil {
+					fmt.Fprintln(os.Stderr, "Error sending notifications:", err)
+				}
+			}
+
+			if err := writeMetricsFile(metricsFile, reg); err != nil {
+				fmt.Fprintln(os.Stderr, "Error writing metrics:", err)
+			}
+
+			status := fmt.Sprintf("%d change(s)", len(c.Events))
+			if c.Baseline {
+				status = "baseline set"
+			}
+			fmt.Fprintf(os.Stderr, "Scanned %d ports in %s, %s; next scan at %s\n",
+				len(c.Results), c.End.Sub(c.Start).Round(time.Millisecond), status,
+				schedule.Next(time.Now()).Format(time.DateTime))
+			return nil
+		})
+		watcher.SetStateFile(watchState)
+
+		if err := watcher.Run(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// eventPrinter returns a function that prints watch events to w in format.
+func eventPrinter(format string, w io.Writer) (func(watch.Event) error, error) {
+	switch format {
+	case "text":
+		return func(e watch.Event) error {
+			_, err := fmt.Fprintln(w, e)
+			return err
+		}, nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		return func(e watch.Event) error {
+			return encoder.Encode(e)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown event format: %s", format)
+	}
+}
+
+// openEventOutputs opens the --event-output files, given as FORMAT:FILE, for
+// appending, so that a restarted watch adds to the events already written.
+// It returns a printer for each file.
+func openEventOutputs(specs []string) ([]func(watch.Event) error, []*os.File, error) {
+	var printers []func(watch.Event) error
+	var files []*os.File
+	for _, spec := range specs {
+		format, path, ok := strings.Cut(spec, ":")
+		if !ok || format == "" || path == "" {
+			return nil, nil, errors.Join(fmt.Errorf("invalid --event-output %q: expected FORMAT:FILE", spec), closeEventOutputs(files))
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("opening event output: %w", err), closeEventOutputs(files))
+		}
+		files = append(files, f)
+
+		write, err := eventPrinter(format, f)
+		if err != nil {
+			return nil, nil, errors.Join(err, closeEventOutputs(files))
+		}
+		printers = append(printers, write)
+	}
+	return printers, files, nil
+}
+
+// closeEventOutputs closes every event output file.
+func closeEventOutputs(files []*os.File) error {
+	var errs []error
+	for _, f := range files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Hour, "Time between the end of one scan and the start of the next")
+	watchCmd.Flags().StringVar(&watchCron, "cron", "", `Start scans on a cron schedule instead, e.g. "0 */6 * * *" or "@daily"`)
+	watchCmd.Flags().StringVar(&watchState, "state", "", "Keep the latest results in this JSON file, to compare with after a restart")
+	watchCmd.Flags().StringVarP(&eventFormat, "event-format", "f", "text", "Event format: text, or json for one object per line")
+	watchCmd.Flags().StringArrayVar(&eventOutputs, "event-output", nil, "Also append events to a file, as FORMAT:FILE with FORMAT text or json, e.g. json:events.ndjson (repeatable)")
+	watchCmd.Flags().StringVar(&notifyFile, "notify", "", "Send notifications about changes that match the rules in a YAML or JSON file")
+	watchCmd.Flags().StringVar(&metricsListen, "metrics-listen", "", "Serve Prometheus metrics on /metrics at this address, e.g. 127.0.0.1:9100")
+	watchCmd.Flags().StringVar(&metricsFile, "metrics-file", "", "Write Prometheus metrics to this file after each scan, for the node exporter's textfile collector")
+	addProbeFlags(watchCmd.Flags())
+	addConfigFlags(watchCmd.Flags())
+	bindEnv(watchCmd.Flags())
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenEventOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o644))
+
+	printers, files, err := openEventOutputs([]string{"json:" + path})
+	require.NoError(t, err)
+	require.Len(t, printers, 1)
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, printers[0](watch.Event{Time: at, Type: watch.PortOpened, Host: "10.0.0.1", Port: 22}))
+	require.NoError(t, closeEventOutputs(files))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n"+`{"time":"2024-01-01T12:00:00Z","type":"port_opened","host":"10.0.0.1","port":22}`+"\n", string(data))
+
+	_, _, err = openEventOutputs([]string{path})
+	assert.ErrorContains(t, err, "expected FORMAT:FILE")
+	_, _, err = openEventOutputs([]string{"csv:" + path})
+	assert.EqualError(t, err, "unknown event format: csv")
+}
@@ -0,0 +1,344 @@
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// A checkpoint file is a line of JSON describing the scan followed by a line
+// of JSON per checkpoint. Probes are numbered host by host, so that probe i
+// is port i%len(ports) on host i/len(ports), and each checkpoint records the
+// number of the first probe that is not done, the probes after it that are,
+// and the results completed since the previous checkpoint. When probes are
+// started in order by a limited number of workers, only that many probes
+// after the first one that is not done can have finished, which keeps
+// checkpoints small.
+
+// version is the version of the checkpoint file format.
+const version = 1
+
+// DefaultInterval is how often a Checkpointer writes a checkpoint.
+const DefaultInterval = 30 * time.Second
+
+// DefaultConcurrency is the number of ports a checkpointed scan probes at
+// once when no limit is given. Without a limit every probe starts at once
+// and the probes that are done can't be recorded compactly.
+const DefaultConcurrency = 1000
+
+// header is the first line of a checkpoint file.
+type header struct {
+	Version int      `json:"version"`
+	Targets []string `json:"targets"`
+	Ports   string   `json:"ports"`
+}
+
+// entry is a single checkpoint.
+type entry struct {
+	Next    int      `json:"next"`
+	Done    []int    `json:"done,omitempty"`
+	Results []result `json:"results,omitempty"`
+}
+
+// result is a completed probe.
+type result struct {
+	Host    string         `json:"host"`
+	Port    int            `json:"port"`
+	Status  scanner.Status `json:"status"`
+	Latency time.Duration  `json:"latency,omitempty"`
+	Banner  string         `json:"banner,omitempty"`
+
+	Unreachable bool `json:"unreachable,omitempty"`
+}
+
+// State is the progress of a scan, read from a checkpoint file.
+type State struct {
+	Targets []string
+	Ports   string
+	// Results holds the results of every probe that is done.
+	Results []scanner.Port
+
+	next int
+	done map[int]bool
+	// size is the length of the file up to the end of the last complete
+	// checkpoint.
+	size int64
+}
+
+// Load reads the state of a scan from a checkpoint file. A checkpoint that
+// was cut short, for example because the machine lost power while it was
+// written, is ignored.
+func Load(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	state, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return state, nil
+}
+
+// read reads a checkpoint file.
+func read(r io.Reader) (*State, error) {
+	reader := bufio.NewReader(r)
+	state := &State{done: map[int]bool{}}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, errors.New("not a checkpoint file")
+	}
+	var h header
+	if err := json.Unmarshal(line, &h); err != nil || h.Version == 0 {
+		return nil, errors.New("not a checkpoint file")
+	}
+	if h.Version != version {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", h.Version)
+	}
+	state.Targets = h.Targets
+	state.Ports = h.Ports
+	state.size = int64(len(line))
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A final line without a newline was not completely written.
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint: %w", err)
+		}
+
+		state.next = e.Next
+		state.done = make(map[int]bool, len(e.Done))
+		for _, i := range e.Done {
+			state.done[i] = true
+		}
+		for _, r := range e.Results {
+			state.Results = append(state.Results, scanner.Port(r))
+		}
+		state.size += int64(len(line))
+	}
+
+	return state, nil
+}
+
+// Done reports whether probe i is done.
+func (s *State) Done(i int) bool {
+	return i < s.next || s.done[i]
+}
+
+// Targets returns the ports to scan on each host in probe order, leaving
+// out those already done if state is not nil.
+func Targets(hosts []string, ports []int, state *State) []scanner.Port {
+	var targets []scanner.Port
+	ports = distinct(ports)
+	for h, host := range hosts {
+		for p, port := range ports {
+			if state != nil && state.Done(h*len(ports)+p) {
+				continue
+			}
+			targets = append(targets, scanner.Port{Host: host, Port: port})
+		}
+	}
+	return targets
+}
+
+// distinct returns ports without repeats, in order. Targets and the
+// Checkpointer number probes by it, so that a repeated port can't leave a
+// probe that is never marked done.
+func distinct(ports []int) []int {
+	seen := make(map[int]bool, len(ports))
+	var result []int
+	for _, port := range ports {
+		if !seen[port] {
+			seen[port] = true
+			result = append(result, port)
+		}
+	}
+	return result
+}
+
+// Checkpointer is an OutputWriter that periodically writes the progress of
+// a scan to a checkpoint file. It must be given every result, unfiltered.
+type Checkpointer struct {
+	file      *os.File
+	hosts     map[string]int
+	ports     map[int]int
+	portCount int
+	total     int
+	interval  time.Duration
+	last      time.Time
+
+	next    int
+	done    map[int]bool
+	pending []result
+}
+
+// Create creates a new checkpoint file for a scan of ports on hosts. It
+// fails if the file already exists, so that a previous scan's progress is
+// not lost by mistake.
+func Create(path string, targets []string, portSpec string, hosts []string, ports []int) (*Checkpointer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("checkpoint file %s already exists, use --resume to continue the scan", path)
+	} else if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(header{Version: version, Targets: targets, Ports: portSpec})
+	if err == nil {
+		_, err = f.Write(append(data, '\n'))
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return newCheckpointer(f, hosts, ports, &State{done: map[int]bool{}}), nil
+}
+
+// Resume reopens the checkpoint file that state was loaded from to record
+// the rest of the scan.
+func Resume(path string, state *State, hosts []string, ports []int) (*Checkpointer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop a checkpoint that was cut short, so the next one starts on a
+	// line of its own.
+	if err := f.Truncate(state.size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(state.size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return newCheckpointer(f, hosts, ports, state), nil
+}
+
+func newCheckpointer(f *os.File, hosts []string, ports []int, state *State) *Checkpointer {
+	ports = distinct(ports)
+	c := &Checkpointer{
+		file:      f,
+		hosts:     make(map[string]int, len(hosts)),
+		ports:     make(map[int]int, len(ports)),
+		portCount: len(ports),
+		total:     len(hosts) * len(ports),
+		interval:  DefaultInterval,
+		next:      state.next,
+		done:      make(map[int]bool, len(state.done)),
+	}
+	for i, host := range hosts {
+		c.hosts[host] = i
+	}
+	for i, port := range ports {
+		c.ports[port] = i
+	}
+	for i := range state.done {
+		c.done[i] = true
+	}
+	return c
+}
+
+// SetInterval sets how often a checkpoint is written.
+func (c *Checkpointer) SetInterval(interval time.Duration) {
+	c.interval = interval
+}
+
+// Complete reports whether every probe is done.
+func (c *Checkpointer) Complete() bool {
+	return c.next >= c.total
+}
+
+// Begin starts the checkpoint interval.
+func (c *Checkpointer) Begin(run *output.ScanRun) error {
+	c.last = time.Now()
+	return nil
+}
+
+// WriteResult marks the result's probe as done, and writes a checkpoint if
+// the interval has passed. Results of probes that were already done, such
+// as those replayed from an earlier checkpoint, are ignored.
+func (c *Checkpointer) WriteResult(r output.Result) error {
+	h, ok1 := c.hosts[r.Host]
+	p, ok2 := c.ports[r.Port]
+	if !ok1 || !ok2 {
+		return fmt.Errorf("checkpoint: %s:%d is not a target of the scan", r.Host, r.Port)
+	}
+
+	i := h*c.portCount + p
+	if i < c.next || c.done[i] {
+		return nil
+	}
+	c.done[i] = true
+	for c.done[c.next] {
+		delete(c.done, c.next)
+		c.next++
+	}
+	c.pending = append(c.pending, result{
+		Host:    r.Host,
+		Port:    r.Port,
+		Status:  r.Status,
+		Latency: r.Latency,
+		Banner:  r.Banner,
+
+		Unreachable: r.Unreachable,
+	})
+
+	if time.Since(c.last) < c.interval {
+		return nil
+	}
+	return c.flush()
+}
+
+// End writes a final checkpoint and closes the file.
+func (c *Checkpointer) End(run *output.ScanRun) error {
+	return errors.Join(c.flush(), c.file.Close())
+}
+
+// flush appends a checkpoint to the file and syncs it to disk.
+func (c *Checkpointer) flush() error {
+	c.last = time.Now()
+	if len(c.pending) == 0 {
+		return nil
+	}
+
+	e := entry{Next: c.next, Results: c.pending}
+	for i := range c.done {
+		e.Done = append(e.Done, i)
+	}
+	slices.Sort(e.Done)
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := c.file.Sync(); err != nil {
+		return err
+	}
+
+	c.pending = c.pending[:0]
+	return nil
+}
@@ -0,0 +1,170 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testHosts = []string{"10.0.0.1", "10.0.0.2"}
+	testPorts = []int{22, 80, 443}
+)
+
+func writeResults(t *testing.T, c *Checkpointer, ports ...scanner.Port) {
+	t.Helper()
+	for _, p := range ports {
+		require.NoError(t, c.WriteResult(output.NewResult(p)))
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.state")
+
+	c, err := Create(path, []string{"10.0.0.0/30"}, "22,80,443", testHosts, testPorts)
+	require.NoError(t, err)
+	c.SetInterval(0)
+	require.NoError(t, c.Begin(&output.ScanRun{}))
+
+	// Probes 0, 1 and 3 are done, but 2 is not.
+	writeResults(t, c,
+		scanner.Port{Host: "10.0.0.1", Port: 80, Status: scanner.Closed},
+		scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: time.Millisecond, Banner: "SSH-2.0"},
+		scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Timeout},
+	)
+	assert.False(t, c.Complete())
+	require.NoError(t, c.End(&output.ScanRun{}))
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/30"}, state.Targets)
+	assert.Equal(t, "22,80,443", state.Ports)
+	assert.ElementsMatch(t, []scanner.Port{
+		{Host: "10.0.0.1", Port: 80, Status: scanner.Closed},
+		{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: time.Millisecond, Banner: "SSH-2.0"},
+		{Host: "10.0.0.2", Port: 22, Status: scanner.Timeout},
+	}, state.Results)
+
+	for i, done := range []bool{true, true, false, true, false, false} {
+		assert.Equal(t, done, state.Done(i), "probe %d", i)
+	}
+	assert.Equal(t, []scanner.Port{
+		{Host: "10.0.0.1", Port: 443},
+		{Host: "10.0.0.2", Port: 80},
+		{Host: "10.0.0.2", Port: 443},
+	}, Targets(testHosts, testPorts, state))
+
+	// Simulate a checkpoint cut short by a crash.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"next":6,"resu`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	state, err = Load(path)
+	require.NoError(t, err)
+	assert.False(t, state.Done(2))
+
+	c, err = Resume(path, state, testHosts, testPorts)
+	require.NoError(t, err)
+	require.NoError(t, c.Begin(&output.ScanRun{}))
+	for _, p := range state.Results {
+		writeResults(t, c, p)
+	}
+	writeResults(t, c,
+		scanner.Port{Host: "10.0.0.1", Port: 443, Status: scanner.Closed},
+		scanner.Port{Host: "10.0.0.2", Port: 80, Status: scanner.Open},
+		scanner.Port{Host: "10.0.0.2", Port: 443, Status: scanner.Closed},
+	)
+	assert.True(t, c.Complete())
+	require.NoError(t, c.End(&output.ScanRun{}))
+
+	state, err = Load(path)
+	require.NoError(t, err)
+	assert.Len(t, state.Results, 6, "replayed results are not recorded again")
+	assert.Empty(t, Targets(testHosts, testPorts, state))
+}
+
+func TestCheckpointer_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.state")
+
+	c, err := Create(path, []string{"10.0.0.0/30"}, "22,80,443", testHosts, testPorts)
+	require.NoError(t, err)
+	c.SetInterval(time.Hour)
+	require.NoError(t, c.Begin(&output.ScanRun{}))
+	writeResults(t, c, scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open})
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, state.Results, "no checkpoint before the interval")
+
+	require.NoError(t, c.End(&output.ScanRun{}))
+	state, err = Load(path)
+	require.NoError(t, err)
+	assert.Len(t, state.Results, 1)
+}
+
+func TestCheckpointer_RepeatedPorts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.state")
+	ports := []int{22, 80, 22}
+
+	assert.Len(t, Targets(testHosts, ports, nil), 4)
+
+	c, err := Create(path, nil, "22,80,22", testHosts, ports)
+	require.NoError(t, err)
+	require.NoError(t, c.Begin(&output.ScanRun{}))
+	for _, p := range Targets(testHosts, ports, nil) {
+		writeResults(t, c, p)
+	}
+	assert.True(t, c.Complete())
+	require.NoError(t, c.End(&output.ScanRun{}))
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, Targets(testHosts, ports, state))
+}
+
+func TestCheckpointer_UnknownTarget(t *testing.T) {
+	c, err := Create(filepath.Join(t.TempDir(), "scan.state"), nil, "22", testHosts, []int{22})
+	require.NoError(t, err)
+	err = c.WriteResult(output.Result{Host: "10.0.0.1", Port: 80})
+	assert.EqualError(t, err, "checkpoint: 10.0.0.1:80 is not a target of the scan")
+}
+
+func TestCreate_Exists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.state")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	_, err := Create(path, nil, "22", testHosts, testPorts)
+	assert.ErrorContains(t, err, "already exists, use --resume")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name     string
+		contents string
+		err      string
+	}{
+		{"empty", "", "not a checkpoint file"},
+		{"not json", "IP Address,Port\n", "not a checkpoint file"},
+		{"version", `{"version":2}` + "\n", "unsupported checkpoint version 2"},
+		{"bad checkpoint", `{"version":1}` + "\n{]\n", "invalid checkpoint"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			require.NoError(t, os.WriteFile(path, []byte(tc.contents), 0o644))
+			_, err := Load(path)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings maps flag names to their values. A flag given a list in the
+// config file has one value per item.
+type Settings map[string][]string
+
+// UnmarshalYAML decodes a mapping of flag names to a value or a list of
+// values.
+func (s *Settings) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of flag names to values", value.Line)
+	}
+
+	*s = Settings{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, node := value.Content[i], value.Content[i+1]
+		switch node.Kind {
+		case yaml.ScalarNode:
+			(*s)[key.Value] = []string{node.Value}
+		case yaml.SequenceNode:
+			var items []string
+			if err := node.Decode(&items); err != nil {
+				return fmt.Errorf("line %d: %s must be a list of values", node.Line, key.Value)
+			}
+			(*s)[key.Value] = items
+		default:
+			return fmt.Errorf("line %d: %s must be a value or a list of values", node.Line, key.Value)
+		}
+	}
+	return nil
+}
+
+// Config is a config file: default settings and named profiles of
+// settings.
+type Config struct {
+	Settings Settings
+	Profiles map[string]Settings
+}
+
+// UnmarshalYAML decodes the settings at the top level of the file and the
+// profiles under its profiles key.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of flag names to values", value.Line)
+	}
+
+	settings := *value
+	settings.Content = nil
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "profiles" {
+			if err := value.Content[i+1].Decode(&c.Profiles); err != nil {
+				return err
+			}
+			continue
+		}
+		settings.Content = append(settings.Content, value.Content[i], value.Content[i+1])
+	}
+	return settings.Decode(&c.Settings)
+}
+
+// builtinProfiles are the profiles available without a config file. A
+// profile of the same name in the config file replaces them.
+var builtinProfiles = map[string]Settings{
+	"quick":     {"ports": {"top100"}, "timeout": {"500ms"}},
+	"full":      {"ports": {"1-65535"}, "retries": {"2"}},
+	"prod-safe": {"rate": {"50"}},
+}
+
+// Profile returns the named profile from the config file, or the built-in
+// profile of that name.
+func (c *Config) Profile(name string) (Settings, error) {
+	if profile, ok := c.Profiles[name]; ok {
+		return profile, nil
+	}
+	if profile, ok := builtinProfiles[name]; ok {
+		return profile, nil
+	}
+	return nil, fmt.Errorf("unknown profile: %s", name)
+}
+
+// DefaultPath returns the path of the config file used when none is given:
+// network-scanner/config.yaml in $XDG_CONFIG_HOME, or in ~/.config if that
+// is not set.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "network-scanner", "config.yaml"), nil
+}
+
+// Load reads a YAML config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Settings: Settings{}}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
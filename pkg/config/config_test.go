@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+timeout: 2s
+show-open: true
+output: [json:scan.json, csv:scan.csv]
+profile: office
+profiles:
+  office:
+    ports: [22, 80, 443]
+    concurrency: 100
+  quick:
+    timeout: 200ms
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Settings{
+		"timeout":   {"2s"},
+		"show-open": {"true"},
+		"output":    {"json:scan.json", "csv:scan.csv"},
+		"profile":   {"office"},
+	}, cfg.Settings)
+
+	office, err := cfg.Profile("office")
+	require.NoError(t, err)
+	assert.Equal(t, Settings{"ports": {"22", "80", "443"}, "concurrency": {"100"}}, office)
+
+	// A profile in the file replaces the built-in one of the same name.
+	quick, err := cfg.Profile("quick")
+	require.NoError(t, err)
+	assert.Equal(t, Settings{"timeout": {"200ms"}}, quick)
+
+	full, err := cfg.Profile("full")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, full["retries"])
+
+	_, err = cfg.Profile("missing")
+	assert.EqualError(t, err, "unknown profile: missing")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"list.yaml":    "- timeout\n",
+		"nested.yaml":  "timeout:\n  value: 2s\n",
+		"profile.yaml": "profiles:\n  quick: [timeout]\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		_, err := Load(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/etc/xdg")
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/xdg/network-scanner/config.yaml", path)
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	path, err = DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/home/user/.config/network-scanner/config.yaml", path)
+}
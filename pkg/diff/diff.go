@@ -0,0 +1,199 @@
+package diff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// PortState is the state of a port in one of the scans being compared.
+type PortState struct {
+	Status  scanner.Status `json:"status"`
+	Service string         `json:"service,omitempty"`
+	Banner  string         `json:"banner,omitempty"`
+}
+
+// PortChange is a change to a single port between two scans. Before is nil
+// if the port was not in the earlier scan and After is nil if it is not in
+// the later one.
+type PortChange struct {
+	Host   string     `json:"host"`
+	Port   int        `json:"port"`
+	Before *PortState `json:"before"`
+	After  *PortState `json:"after"`
+}
+
+// Report lists the differences between two scans.
+type Report struct {
+	NewHosts      []string     `json:"new_hosts"`
+	VanishedHosts []string     `json:"vanished_hosts"`
+	Opened        []PortChange `json:"opened"`
+	Closed        []PortChange `json:"closed"`
+	Changed       []PortChange `json:"changed"`
+}
+
+// Compare compares the results of an earlier scan with a later one.
+//
+// A host is considered up if any of its ports answered, whether open or
+// closed. A port that was open and is now closed, timed out or missing
+// counts as closed, so the open ports of a vanished host are listed too.
+// Open ports whose service or banner changed are reported as changed.
+func Compare(before, after []output.Result) *Report {
+	beforePorts, beforeUp := index(before)
+	afterPorts, afterUp := index(after)
+
+	report := &Report{
+		NewHosts:      []string{},
+		VanishedHosts: []string{},
+		Opened:        []PortChange{},
+		Closed:        []PortChange{},
+		Changed:       []PortChange{},
+	}
+
+	for host := range afterUp {
+		if !beforeUp[host] {
+			report.NewHosts = append(report.NewHosts, host)
+		}
+	}
+	for host := range beforeUp {
+		if !afterUp[host] {
+			report.VanishedHosts = append(report.VanishedHosts, host)
+		}
+	}
+
+	for key, b := range beforePorts {
+		a, ok := afterPorts[key]
+		change := PortChange{Host: key.host, Port: key.port, Before: b}
+		if ok {
+			change.After = a
+		}
+
+		switch {
+		case b.Status == scanner.Open && (!ok || a.Status != scanner.Open):
+			report.Closed = append(report.Closed, change)
+		case b.Status != scanner.Open && ok && a.Status == scanner.Open:
+			report.Opened = append(report.Opened, change)
+		case b.Status == scanner.Open && (a.Service != b.Service || a.Banner != b.Banner):
+			report.Changed = append(report.Changed, change)
+		}
+	}
+	for key, a := range afterPorts {
+		if _, ok := beforePorts[key]; !ok && a.Status == scanner.Open {
+			report.Opened = append(report.Opened, PortChange{Host: key.host, Port: key.port, After: a})
+		}
+	}
+
+	slices.SortFunc(report.NewHosts, output.CompareHosts)
+	slices.SortFunc(report.VanishedHosts, output.CompareHosts)
+	for _, changes := range [][]PortChange{report.Opened, report.Closed, report.Changed} {
+		slices.SortFunc(changes, func(a, b PortChange) int {
+			if c := output.CompareHosts(a.Host, b.Host); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Port, b.Port)
+		})
+	}
+
+	return report
+}
+
+// Empty reports whether the scans had no differences.
+func (r *Report) Empty() bool {
+	return len(r.NewHosts) == 0 && len(r.VanishedHosts) == 0 &&
+		len(r.Opened) == 0 && len(r.Closed) == 0 && len(r.Changed) == 0
+}
+
+// WriteJSON writes the report as a JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if r.Empty() {
+		b.WriteString("No changes.\n")
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", title, len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	describe := func(changes []PortChange, detail func(PortChange) string) []string {
+		lines := make([]string, len(changes))
+		for i, c := range changes {
+			lines[i] = fmt.Sprintf("%s %s", net.JoinHostPort(c.Host, strconv.Itoa(c.Port)), detail(c))
+		}
+		return lines
+	}
+
+	section("New hosts", r.NewHosts)
+	section("Vanished hosts", r.VanishedHosts)
+	section("Newly opened ports", describe(r.Opened, func(c PortChange) string {
+		return describeState(c.After)
+	}))
+	section("Newly closed ports", describe(r.Closed, func(c PortChange) string {
+		if c.After == nil {
+			return "no longer seen"
+		}
+		return "now " + c.After.Status.String()
+	}))
+	section("Changed services", describe(r.Changed, func(c PortChange) string {
+		return fmt.Sprintf("%s -> %s", describeState(c.Before), describeState(c.After))
+	}))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// index maps each port to its state and records which hosts answered.
+func index(results []output.Result) (map[portKey]*PortState, map[string]bool) {
+	ports := make(map[portKey]*PortState, len(results))
+	up := make(map[string]bool)
+	for _, r := range results {
+		ports[portKey{host: r.Host, port: r.Port}] = &PortState{Status: r.Status, Service: r.Service, Banner: r.Banner}
+		if r.Status != scanner.Timeout {
+			up[r.Host] = true
+		}
+	}
+	return ports, up
+}
+
+// portKey identifies a port on a host.
+type portKey struct {
+	host string
+	port int
+}
+
+// describeState summarises a port's service and banner.
+func describeState(s *PortState) string {
+	if s == nil {
+		return "(none)"
+	}
+	parts := []string{}
+	if s.Service != "" {
+		parts = append(parts, s.Service)
+	}
+	if s.Banner != "" {
+		parts = append(parts, fmt.Sprintf("%q", s.Banner))
+	}
+	if len(parts) == 0 {
+		return "(unknown service)"
+	}
+	return strings.Join(parts, " ")
+}
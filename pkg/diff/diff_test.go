@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func result(host string, port int, status scanner.Status, banner string) output.Result {
+	return output.NewResult(scanner.Port{Host: host, Port: port, Status: status, Banner: banner})
+}
+
+var (
+	lastWeek = []output.Result{
+		result("10.0.0.1", 22, scanner.Open, "SSH-2.0-OpenSSH_8.9"),
+		result("10.0.0.1", 80, scanner.Open, ""),
+		result("10.0.0.1", 443, scanner.Closed, ""),
+		result("10.0.0.2", 22, scanner.Open, ""),
+		result("10.0.0.10", 3389, scanner.Timeout, ""),
+	}
+	thisWeek = []output.Result{
+		result("10.0.0.1", 22, scanner.Open, "SSH-2.0-OpenSSH_9.6"),
+		result("10.0.0.1", 443, scanner.Open, ""),
+		result("10.0.0.3", 8080, scanner.Open, ""),
+		result("10.0.0.10", 3389, scanner.Timeout, ""),
+	}
+)
+
+func TestCompare(t *testing.T) {
+	report := Compare(lastWeek, thisWeek)
+
+	assert.Equal(t, []string{"10.0.0.3"}, report.NewHosts)
+	assert.Equal(t, []string{"10.0.0.2"}, report.VanishedHosts)
+
+	assert.Equal(t, []PortChange{
+		{Host: "10.0.0.1", Port: 443, Before: &PortState{Status: scanner.Closed, Service: "https"}, After: &PortState{Status: scanner.Open, Service: "https"}},
+		{Host: "10.0.0.3", Port: 8080, After: &PortState{Status: scanner.Open, Service: "http-proxy"}},
+	}, report.Opened)
+
+	assert.Equal(t, []PortChange{
+		{Host: "10.0.0.1", Port: 80, Before: &PortState{Status: scanner.Open, Service: "http"}},
+		{Host: "10.0.0.2", Port: 22, Before: &PortState{Status: scanner.Open, Service: "ssh"}},
+	}, report.Closed)
+
+	assert.Equal(t, []PortChange{
+		{
+			Host:   "10.0.0.1",
+			Port:   22,
+			Before: &PortState{Status: scanner.Open, Service: "ssh", Banner: "SSH-2.0-OpenSSH_8.9"},
+			After:  &PortState{Status: scanner.Open, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+		},
+	}, report.Changed)
+
+	assert.False(t, report.Empty())
+}
+
+func TestCompare_VanishedHost(t *testing.T) {
+	before := []output.Result{
+		result("10.0.0.1", 22, scanner.Open, ""),
+		result("10.0.0.1", 80, scanner.Open, ""),
+	}
+	after := []output.Result{
+		result("10.0.0.1", 22, scanner.Timeout, ""),
+	}
+	report := Compare(before, after)
+
+	assert.Equal(t, []string{"10.0.0.1"}, report.VanishedHosts)
+	assert.Equal(t, []PortChange{
+		{Host: "10.0.0.1", Port: 22, Before: &PortState{Status: scanner.Open, Service: "ssh"}, After: &PortState{Status: scanner.Timeout, Service: "ssh"}},
+		{Host: "10.0.0.1", Port: 80, Before: &PortState{Status: scanner.Open, Service: "http"}},
+	}, report.Closed)
+}
+
+func TestCompare_NoChanges(t *testing.T) {
+	report := Compare(thisWeek, thisWeek)
+	assert.True(t, report.Empty())
+
+	writer := &bytes.Buffer{}
+	require.NoError(t, report.WriteText(writer))
+	assert.Equal(t, "No changes.\n", writer.String())
+}
+
+func TestReport_WriteText(t *testing.T) {
+	writer := &bytes.Buffer{}
+	require.NoError(t, Compare(lastWeek, thisWeek).WriteText(writer))
+
+	expected := "New hosts (1):\n" +
+		"  10.0.0.3\n" +
+		"Vanished hosts (1):\n" +
+		"  10.0.0.2\n" +
+		"Newly opened ports (2):\n" +
+		"  10.0.0.1:443 https\n" +
+		"  10.0.0.3:8080 http-proxy\n" +
+		"Newly closed ports (2):\n" +
+		"  10.0.0.1:80 no longer seen\n" +
+		"  10.0.0.2:22 no longer seen\n" +
+		"Changed services (1):\n" +
+		"  10.0.0.1:22 ssh \"SSH-2.0-OpenSSH_8.9\" -> ssh \"SSH-2.0-OpenSSH_9.6\"\n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	writer := &bytes.Buffer{}
+	require.NoError(t, Compare(lastWeek, thisWeek).WriteJSON(writer))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(writer.Bytes(), &doc))
+	assert.Equal(t, []any{"10.0.0.3"}, doc["new_hosts"])
+
+	opened := doc["opened"].([]any)
+	require.Len(t, opened, 2)
+	first := opened[0].(map[string]any)
+	assert.Equal(t, "10.0.0.1", first["host"])
+	assert.Equal(t, "closed", first["before"].(map[string]any)["status"])
+	assert.Equal(t, "open", first["after"].(map[string]any)["status"])
+}
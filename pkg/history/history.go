@@ -0,0 +1,250 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// runsBucket holds a Run per scan, keyed by run ID.
+	runsBucket = []byte("runs")
+	// resultsBucket holds a nested bucket of results per run, keyed by run
+	// ID and then by portKey.
+	resultsBucket = []byte("results")
+	// portsBucket holds a PortHistory per port that has ever been open,
+	// keyed by portKey.
+	portsBucket = []byte("ports")
+)
+
+// ErrNotFound is returned when a run does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Run is a scan recorded in the history.
+type Run struct {
+	ID      uint64    `json:"id"`
+	Command string    `json:"command"`
+	Targets []string  `json:"targets"`
+	Ports   string    `json:"ports"`
+	Start   time.Time `json:"start"`
+	// End is zero if the scan did not finish.
+	End     time.Time `json:"end"`
+	Hosts   int       `json:"hosts"`
+	Open    int       `json:"open"`
+	Closed  int       `json:"closed"`
+	Timeout int       `json:"timeout"`
+}
+
+// PortHistory is the history of a single port on a single host. Ports are
+// only tracked once they have been seen open.
+type PortHistory struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// FirstOpen and LastOpen are the start times of the first and latest
+	// scans that found the port open.
+	FirstOpen time.Time `json:"first_open"`
+	LastOpen  time.Time `json:"last_open"`
+	// LastSeen is the start time of the latest scan of the port, and
+	// LastStatus its result.
+	LastSeen   time.Time      `json:"last_seen"`
+	LastStatus scanner.Status `json:"last_status"`
+	// Scans is the number of scans of the port and OpenCount the number
+	// that found it open.
+	Scans     int `json:"scans"`
+	OpenCount int `json:"open_count"`
+	// Flaps is the number of times the port changed between open and not
+	// open.
+	Flaps   int    `json:"flaps"`
+	Service string `json:"service,omitempty"`
+	// Banner is the latest banner read from the port.
+	Banner string `json:"banner,omitempty"`
+}
+
+// storedResult is a result as stored under its run.
+type storedResult struct {
+	Status  scanner.Status `json:"status"`
+	Latency time.Duration  `json:"latency,omitempty"`
+	Banner  string         `json:"banner,omitempty"`
+	Service string         `json:"service,omitempty"`
+}
+
+// Store is a scan history kept in a bbolt database file.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the history database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening history %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{runsBucket, resultsBucket, portsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Runs returns every recorded run, oldest first.
+func (s *Store) Runs() ([]Run, error) {
+	runs := []Run{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).ForEach(func(k, v []byte) error {
+			var run Run
+			if err := json.Unmarshal(v, &run); err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	return runs, err
+}
+
+// Run returns a single run.
+func (s *Store) Run(id uint64) (*Run, error) {
+	var run *Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(runsBucket).Get(runKey(id))
+		if v == nil {
+			return fmt.Errorf("run %d: %w", id, ErrNotFound)
+		}
+		run = &Run{}
+		return json.Unmarshal(v, run)
+	})
+	return run, err
+}
+
+// Results returns the results of a run, ordered by host and port.
+func (s *Store) Results(id uint64) ([]output.Result, error) {
+	results := []output.Result{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resultsBucket).Bucket(runKey(id))
+		if b == nil {
+			return fmt.Errorf("run %d: %w", id, ErrNotFound)
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var stored storedResult
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return err
+			}
+			host, port := parsePortKey(k)
+			results = append(results, output.Result{
+				Host:    host,
+				Port:    port,
+				Status:  stored.Status,
+				Latency: stored.Latency,
+				Banner:  stored.Banner,
+				Service: stored.Service,
+			})
+			return nil
+		})
+	})
+	return results, err
+}
+
+// Query selects port histories. The zero Query selects every port that has
+// ever been open.
+type Query struct {
+	// Hosts limits the query to hosts in these ranges.
+	Hosts []netip.Prefix
+	// Ports limits the query to these ports.
+	Ports []int
+	// Open only selects ports that were open in their latest scan.
+	Open bool
+	// Flapping only selects ports that have changed between open and not
+	// open at least once.
+	Flapping bool
+	// Since only selects ports that were open at or after this time.
+	Since time.Time
+}
+
+// matches reports whether h is selected by the query.
+func (q *Query) matches(h *PortHistory) bool {
+	if len(q.Ports) > 0 && !slices.Contains(q.Ports, h.Port) {
+		return false
+	}
+	if len(q.Hosts) > 0 {
+		addr := netip.MustParseAddr(h.Host)
+		if !slices.ContainsFunc(q.Hosts, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+			return false
+		}
+	}
+	if q.Open && h.LastStatus != scanner.Open {
+		return false
+	}
+	if q.Flapping && h.Flaps == 0 {
+		return false
+	}
+	if !q.Since.IsZero() && h.LastOpen.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// Ports returns the histories of the ports selected by q, ordered by host
+// and port.
+func (s *Store) Ports(q Query) ([]PortHistory, error) {
+	histories := []PortHistory{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(portsBucket).ForEach(func(k, v []byte) error {
+			var h PortHistory
+			if err := json.Unmarshal(v, &h); err != nil {
+				return err
+			}
+			if q.matches(&h) {
+				histories = append(histories, h)
+			}
+			return nil
+		})
+	})
+	return histories, err
+}
+
+// runKey encodes a run ID so that runs sort in the order they were
+// recorded.
+func runKey(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+// portKey encodes a host and port so that keys sort by numeric address and
+// then port.
+func portKey(host string, port int) ([]byte, error) {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %s", host)
+	}
+	a := addr.Unmap().As16()
+	return binary.BigEndian.AppendUint16(a[:], uint16(port)), nil
+}
+
+// parsePortKey decodes a key made by portKey.
+func parsePortKey(k []byte) (string, int) {
+	addr := netip.AddrFrom16([16]byte(k[:16])).Unmap()
+	return addr.String(), int(binary.BigEndian.Uint16(k[16:]))
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// record records a scan that started at start with the given results.
+func record(t *testing.T, store *Store, start time.Time, ports ...scanner.Port) uint64 {
+	t.Helper()
+	ch := make(chan scanner.Port, len(ports))
+	for _, p := range ports {
+		ch <- p
+	}
+	close(ch)
+
+	recorder := NewRecorder(store)
+	run := &output.ScanRun{Targets: []string{"10.0.0.0/24"}, Ports: "22,3389", Start: start}
+	require.NoError(t, output.Stream(recorder, run, ch, nil))
+	return recorder.ID()
+}
+
+func TestRecorder(t *testing.T) {
+	store := openTestStore(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	id := record(t, store, start,
+		scanner.Port{Host: "10.0.0.10", Port: 22, Status: scanner.Open, Latency: time.Millisecond, Banner: "SSH-2.0"},
+		scanner.Port{Host: "10.0.0.9", Port: 3389, Status: scanner.Closed},
+		scanner.Port{Host: "2001:db8::1", Port: 22, Status: scanner.Timeout},
+	)
+	assert.Equal(t, uint64(1), id)
+
+	runs, err := store.Runs()
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "10.0.0.0/24", runs[0].Targets[0])
+	assert.Equal(t, 3, runs[0].Hosts)
+	assert.Equal(t, 1, runs[0].Open)
+	assert.False(t, runs[0].End.IsZero())
+
+	results, err := store.Results(id)
+	require.NoError(t, err)
+	assert.Equal(t, []output.Result{
+		{Host: "10.0.0.9", Port: 3389, Status: scanner.Closed, Service: "ms-wbt-server"},
+		{Host: "10.0.0.10", Port: 22, Status: scanner.Open, Latency: time.Millisecond, Banner: "SSH-2.0", Service: "ssh"},
+		{Host: "2001:db8::1", Port: 22, Status: scanner.Timeout, Service: "ssh"},
+	}, results)
+
+	_, err = store.Results(2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Run(2)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPorts(t *testing.T) {
+	store := openTestStore(t)
+	day := func(n int) time.Time { return time.Date(2024, 1, n, 0, 0, 0, 0, time.UTC) }
+
+	record(t, store, day(1),
+		scanner.Port{Host: "10.0.0.1", Port: 3389, Status: scanner.Closed},
+		scanner.Port{Host: "10.0.0.2", Port: 3389, Status: scanner.Open},
+		scanner.Port{Host: "10.0.0.3", Port: 22, Status: scanner.Open},
+	)
+	record(t, store, day(2),
+		scanner.Port{Host: "10.0.0.1", Port: 3389, Status: scanner.Open},
+		scanner.Port{Host: "10.0.0.2", Port: 3389, Status: scanner.Timeout},
+		scanner.Port{Host: "10.0.0.3", Port: 22, Status: scanner.Open},
+	)
+	record(t, store, day(3),
+		scanner.Port{Host: "10.0.0.1", Port: 3389, Status: scanner.Closed},
+		scanner.Port{Host: "10.0.0.2", Port: 3389, Status: scanner.Open},
+	)
+
+	all, err := store.Ports(Query{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+
+	assert.Equal(t, PortHistory{
+		Host:       "10.0.0.1",
+		Port:       3389,
+		FirstOpen:  day(2),
+		LastOpen:   day(2),
+		LastSeen:   day(3),
+		LastStatus: scanner.Closed,
+		Scans:      2,
+		OpenCount:  1,
+		Flaps:      1,
+		Service:    "ms-wbt-server",
+	}, all[0])
+	assert.Equal(t, 2, all[1].Flaps)
+	assert.Equal(t, 3, all[1].Scans)
+	assert.Equal(t, day(1), all[2].FirstOpen)
+	assert.Equal(t, day(2), all[2].LastSeen)
+
+	testCases := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"ever open", Query{Ports: []int{3389}}, []string{"10.0.0.1", "10.0.0.2"}},
+		{"open now", Query{Ports: []int{3389}, Open: true}, []string{"10.0.0.2"}},
+		{"hosts", Query{Hosts: []netip.Prefix{netip.MustParsePrefix("10.0.0.3/32")}}, []string{"10.0.0.3"}},
+		{"flapping", Query{Flapping: true}, []string{"10.0.0.1", "10.0.0.2"}},
+		{"since", Query{Since: day(3)}, []string{"10.0.0.2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			histories, err := store.Ports(tc.query)
+			require.NoError(t, err)
+			var hosts []string
+			for _, h := range histories {
+				hosts = append(hosts, h.Host)
+			}
+			assert.Equal(t, tc.expected, hosts)
+		})
+	}
+}
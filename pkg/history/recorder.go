@@ -0,0 +1,162 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// recordBatchSize is the number of results written to the database in a
+// single transaction.
+const recordBatchSize = 1000
+
+// Recorder is an OutputWriter that records a scan in a Store. Results are
+// written in batches, so a scan that is interrupted keeps most of its
+// results.
+type Recorder struct {
+	store *Store
+	run   Run
+	batch []output.Result
+}
+
+// NewRecorder creates a new Recorder that records into store.
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// ID returns the ID of the run being recorded. It is set by Begin.
+func (r *Recorder) ID() uint64 {
+	return r.run.ID
+}
+
+// Begin records the start of a run.
+func (r *Recorder) Begin(run *output.ScanRun) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		id, err := runs.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		r.run = Run{
+			ID:      id,
+			Command: run.Command,
+			Targets: run.Targets,
+			Ports:   run.Ports,
+			Start:   run.Start,
+		}
+		if _, err := tx.Bucket(resultsBucket).CreateBucket(runKey(id)); err != nil {
+			return err
+		}
+		return putJSON(runs, runKey(id), r.run)
+	})
+}
+
+// WriteResult records a result.
+func (r *Recorder) WriteResult(result output.Result) error {
+	r.batch = append(r.batch, result)
+	if len(r.batch) < recordBatchSize {
+		return nil
+	}
+	return r.flush()
+}
+
+// End records the remaining results and the end of the run.
+func (r *Recorder) End(run *output.ScanRun) error {
+	if err := r.flush(); err != nil {
+		return err
+	}
+
+	r.run.End = run.End
+	if run.Summary != nil {
+		r.run.Hosts = run.Summary.Hosts
+		r.run.Open = run.Summary.Open
+		r.run.Closed = run.Summary.Closed
+		r.run.Timeout = run.Summary.Timeout
+	}
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(runsBucket), runKey(r.run.ID), r.run)
+	})
+}
+
+// flush writes the batched results and updates the history of their ports.
+func (r *Recorder) flush() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+
+	err := r.store.db.Update(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket).Bucket(runKey(r.run.ID))
+		ports := tx.Bucket(portsBucket)
+
+		for _, result := range r.batch {
+			key, err := portKey(result.Host, result.Port)
+			if err != nil {
+				return err
+			}
+
+			err = putJSON(results, key, storedResult{
+				Status:  result.Status,
+				Latency: result.Latency,
+				Banner:  result.Banner,
+				Service: result.Service,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := r.observe(ports, key, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	r.batch = r.batch[:0]
+	return err
+}
+
+// observe updates the history of a port with a new result. Ports are only
+// tracked once they have been seen open.
+func (r *Recorder) observe(ports *bolt.Bucket, key []byte, result output.Result) error {
+	var h PortHistory
+	if v := ports.Get(key); v != nil {
+		if err := json.Unmarshal(v, &h); err != nil {
+			return fmt.Errorf("history of %s:%d: %w", result.Host, result.Port, err)
+		}
+	} else if result.Status != scanner.Open {
+		return nil
+	} else {
+		h = PortHistory{Host: result.Host, Port: result.Port, FirstOpen: r.run.Start}
+	}
+
+	open := result.Status == scanner.Open
+	if h.Scans > 0 && (h.LastStatus == scanner.Open) != open {
+		h.Flaps++
+	}
+	h.Scans++
+	h.LastSeen = r.run.Start
+	h.LastStatus = result.Status
+	h.Service = result.Service
+	if open {
+		h.OpenCount++
+		h.LastOpen = r.run.Start
+		if result.Banner != "" {
+			h.Banner = result.Banner
+		}
+	}
+
+	return putJSON(ports, key, h)
+}
+
+// putJSON stores v in b as JSON.
+func putJSON(b *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
@@ -2,11 +2,19 @@ package iputil
 
 import (
 	"fmt"
+	"math"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/services"
 )
 
+// TopPorts is the port list that selects the 100 TCP ports most often found
+// open.
+const TopPorts = "top100"
+
 // GetIPs returns a list of IPs from a CIDR range.
 func GetIPs(cidr string) ([]string, error) {
 	ip, ipnet, err := net.ParseCIDR(cidr)
@@ -27,6 +35,45 @@ func GetIPs(cidr string) ([]string, error) {
 	return ips[1 : len(ips)-1], nil
 }
 
+// CountIPs returns how many addresses GetIPs returns for a CIDR range,
+// without listing them. Ranges with too many addresses to count in an int
+// are reported as math.MaxInt.
+func CountIPs(cidr string) (int, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0, err
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones >= strconv.IntSize-1 {
+		return math.MaxInt, nil
+	}
+	n := 1 << (bits - ones)
+	// GetIPs leaves out the network and broadcast addresses.
+	if n < 2 {
+		return n, nil
+	}
+	return n - 2, nil
+}
+
+// ParsePrefix parses a CIDR range or a single address, which is treated as
+// a range containing only that address.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR: %s", s)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid address: %s", s)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 // inc increments an IP address to the next one in the network.
 func inc(ip net.IP) {
 	for j := len(ip) - 1; j >= 0; j-- {
@@ -37,19 +84,28 @@ func inc(ip net.IP) {
 	}
 }
 
-// ParsePorts parses a comma-separated list of ports or a port range.
+// ParsePorts parses a comma-separated list of ports, a port range or
+// TopPorts. A port listed more than once is only returned once.
 func ParsePorts(ports string) ([]int, error) {
 	var result []int
 
+	if ports == TopPorts {
+		return services.TopPorts(), nil
+	}
+
 	// Handle a list of ports
 	if !strings.Contains(ports, "-") {
 		parts := strings.Split(ports, ",")
+		seen := make(map[int]bool, len(parts))
 		for _, part := range parts {
 			port, err := strconv.Atoi(part)
 			if err != nil {
 				return nil, fmt.Errorf("invalid port: %s", part)
 			}
-			result = append(result, port)
+			if !seen[port] {
+				seen[port] = true
+				result = append(result, port)
+			}
 		}
 
 		return result, nil
@@ -1,9 +1,12 @@
 package iputil
 
 import (
+	"math"
 	"net"
 	"reflect"
 	"testing"
+
+	"github.com/theryanhowell/network-scanner/pkg/services"
 )
 
 func TestGetIPs(t *testing.T) {
@@ -144,12 +147,24 @@ func TestParsePorts(t *testing.T) {
 			expected: []int{80, 443, 8080},
 			hasError: false,
 		},
+		{
+			name:     "repeated ports",
+			ports:    "22,80,22",
+			expected: []int{22, 80},
+			hasError: false,
+		},
 		{
 			name:     "port range",
 			ports:    "80-82",
 			expected: []int{80, 81, 82},
 			hasError: false,
 		},
+		{
+			name:     "top ports",
+			ports:    "top100",
+			expected: services.TopPorts(),
+			hasError: false,
+		},
 		{
 			name:     "single port in range",
 			ports:    "80-80",
@@ -207,3 +222,55 @@ func TestParsePorts(t *testing.T) {
 		})
 	}
 }
+
+func TestCountIPs(t *testing.T) {
+	for _, cidr := range []string{"192.168.1.0/30", "192.168.1.0/29", "192.168.1.0/31", "192.168.1.5/32", "10.0.0.0/20", "2001:db8::/120"} {
+		ips, err := GetIPs(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := CountIPs(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != len(ips) {
+			t.Errorf("CountIPs(%s) = %d, GetIPs returned %d", cidr, count, len(ips))
+		}
+	}
+
+	if count, err := CountIPs("2001:db8::/64"); err != nil || count != math.MaxInt {
+		t.Errorf("CountIPs(2001:db8::/64) = %d, %v, want math.MaxInt", count, err)
+	}
+	if _, err := CountIPs("10.0.0.1"); err == nil {
+		t.Error("expected an error for an address without a prefix length")
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{name: "CIDR", input: "10.0.0.0/24", expected: "10.0.0.0/24"},
+		{name: "CIDR with host bits", input: "10.0.0.5/24", expected: "10.0.0.0/24"},
+		{name: "IPv4 address", input: "10.0.0.5", expected: "10.0.0.5/32"},
+		{name: "IPv6 address", input: "2001:db8::1", expected: "2001:db8::1/128"},
+		{name: "invalid CIDR", input: "10.0.0.0/33", hasError: true},
+		{name: "invalid address", input: "example.com", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prefix, err := ParsePrefix(tc.input)
+			if (err != nil) != tc.hasError {
+				t.Errorf("ParsePrefix() error = %v, wantErr %v", err, tc.hasError)
+				return
+			}
+			if !tc.hasError && prefix.String() != tc.expected {
+				t.Errorf("ParsePrefix() = %v, want %v", prefix, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// maxListedFindings is the most findings listed in a chat message. The rest
+// are counted.
+const maxListedFindings = 25
+
+// NewSlack creates a Webhook that posts notifications to a Slack incoming
+// webhook, or any service that accepts Slack's message format.
+func NewSlack(url string) *Webhook {
+	w := NewWebhook(url)
+	w.SetEncoder(encodeSlack)
+	return w
+}
+
+// NewTeams creates a Webhook that posts notifications to a Microsoft Teams
+// workflow webhook as an Adaptive Card.
+func NewTeams(url string) *Webhook {
+	w := NewWebhook(url)
+	w.SetEncoder(encodeTeams)
+	return w
+}
+
+func encodeSlack(n *Notification) ([]byte, error) {
+	lines := findingLines(n, func(s string) string { return "`" + s + "`" })
+	return json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", n.Title, strings.Join(lines, "\n")),
+	})
+}
+
+func encodeTeams(n *Notification) ([]byte, error) {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": n.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+	}
+	for _, line := range findingLines(n, func(s string) string { return s }) {
+		body = append(body, map[string]any{"type": "TextBlock", "text": line, "wrap": true, "spacing": "None"})
+	}
+
+	return json.Marshal(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	})
+}
+
+// findingLines describes each finding in a line of a chat message, using
+// code to format addresses.
+func findingLines(n *Notification, code func(string) string) []string {
+	var lines []string
+	for i, f := range n.Findings {
+		if i == maxListedFindings {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(n.Findings)-i))
+			break
+		}
+
+		target := f.Host
+		if f.Port != 0 {
+			target = net.JoinHostPort(f.Host, strconv.Itoa(f.Port))
+		}
+		line := "• " + code(target)
+		if f.Event != "" {
+			line += " " + strings.ReplaceAll(string(f.Event), "_", " ")
+		} else if f.Status != nil {
+			line += " " + strings.ToLower(f.Status.String())
+		}
+		if f.Service != "" {
+			line += " (" + f.Service + ")"
+		}
+		line += " — " + f.Rule
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeSlack(t *testing.T) {
+	body, err := encodeSlack(testNotification())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text": "*network-scanner: 1 finding(s) in a scan of 10.0.0.0/24*\n• `+"`10.0.0.5:3389`"+` open (ms-wbt-server) — rdp"}`, string(body))
+}
+
+func TestEncodeTeams(t *testing.T) {
+	body, err := encodeTeams(testNotification())
+	require.NoError(t, err)
+
+	var message struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string `json:"type"`
+				Body []struct {
+					Text string `json:"text"`
+				} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal(body, &message))
+
+	assert.Equal(t, "message", message.Type)
+	require.Len(t, message.Attachments, 1)
+	card := message.Attachments[0]
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", card.ContentType)
+	assert.Equal(t, "AdaptiveCard", card.Content.Type)
+	require.Len(t, card.Content.Body, 2)
+	assert.Equal(t, "network-scanner: 1 finding(s) in a scan of 10.0.0.0/24", card.Content.Body[0].Text)
+	assert.Equal(t, "• 10.0.0.5:3389 open (ms-wbt-server) — rdp", card.Content.Body[1].Text)
+}
+
+func TestFindingLines(t *testing.T) {
+	n := &Notification{Findings: []Finding{
+		{Rule: "new hosts", Host: "10.0.0.9", Event: watch.HostAppeared},
+		{Rule: "changes", Host: "2001:db8::1", Port: 22, Event: watch.PortClosed},
+	}}
+	for i := range 30 {
+		n.Findings = append(n.Findings, Finding{Rule: "many", Host: fmt.Sprintf("10.0.1.%d", i), Port: 80})
+	}
+
+	lines := findingLines(n, func(s string) string { return s })
+	require.Len(t, lines, maxListedFindings+1)
+	assert.Equal(t, "• 10.0.0.9 host appeared — new hosts", lines[0])
+	assert.Equal(t, "• [2001:db8::1]:22 port closed — changes", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "• 10.0.1.0:80 — many"))
+	assert.Equal(t, "… and 7 more", lines[maxListedFindings])
+}
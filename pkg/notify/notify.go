@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+)
+
+// Finding is a result or change that matched a notification rule.
+type Finding struct {
+	Rule string `json:"rule"`
+	Host string `json:"host"`
+	// Port is zero for changes to a whole host.
+	Port int `json:"port,omitempty"`
+	// Status is the port's status, or its latest one for a change. It is
+	// nil for a host that vanished.
+	Status  *scanner.Status `json:"status,omitempty"`
+	Service string          `json:"service,omitempty"`
+	Banner  string          `json:"banner,omitempty"`
+	// Event is the kind of change that was found by watch, if any.
+	Event watch.EventType `json:"event,omitempty"`
+}
+
+// Notification is a message about the findings of a scan.
+type Notification struct {
+	Title    string    `json:"title"`
+	Time     time.Time `json:"time"`
+	Targets  []string  `json:"targets"`
+	Findings []Finding `json:"findings"`
+}
+
+// Notifier sends notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
@@ -0,0 +1,301 @@
+package notify
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/policy"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config declares where notifications are sent and what triggers them.
+type Config struct {
+	Notifiers []NotifierConfig `yaml:"notifiers" json:"notifiers"`
+	Rules     []Rule           `yaml:"rules" json:"rules"`
+}
+
+// NotifierConfig configures a single Notifier. Environment variables such as
+// ${SLACK_WEBHOOK} are expanded in the URL, secret and header values, so
+// they need not be stored in the file.
+type NotifierConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is webhook, slack or teams.
+	Type string `yaml:"type" json:"type"`
+	URL  string `yaml:"url" json:"url"`
+	// Secret signs webhook requests with HMAC-SHA256.
+	Secret string `yaml:"secret" json:"secret"`
+	// Template replaces the JSON body of a webhook.
+	Template string            `yaml:"template" json:"template"`
+	Headers  map[string]string `yaml:"headers" json:"headers"`
+	// Retries defaults to DefaultRetries.
+	Retries *int `yaml:"retries" json:"retries"`
+}
+
+// Rule selects the results and changes to notify about.
+//
+// For the results of a scan, a rule matches ports whose status is one of
+// Statuses, open by default. For the changes found by watch, it matches
+// changes whose type is one of Events, port_opened by default. Either can
+// be narrowed to Hosts and Ports.
+type Rule struct {
+	Name     string            `yaml:"name" json:"name"`
+	Hosts    []string          `yaml:"hosts" json:"hosts"`
+	Ports    policy.Ports      `yaml:"ports" json:"ports"`
+	Statuses []scanner.Status  `yaml:"statuses" json:"statuses"`
+	Events   []watch.EventType `yaml:"events" json:"events"`
+	// Notify names the notifiers to send to. Every notifier is used if it
+	// is empty.
+	Notify []string `yaml:"notify" json:"notify"`
+
+	prefixes []netip.Prefix
+}
+
+// Load reads a notification config from a YAML or JSON file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// matchesHost reports whether the rule applies to host.
+func (r *Rule) matchesHost(host string) bool {
+	if len(r.prefixes) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(r.prefixes, func(p netip.Prefix) bool {
+		return p.Contains(addr.Unmap())
+	})
+}
+
+// matchesResult reports whether the rule applies to a scan result.
+func (r *Rule) matchesResult(result output.Result) bool {
+	statuses := r.Statuses
+	if len(statuses) == 0 {
+		statuses = []scanner.Status{scanner.Open}
+	}
+	return slices.Contains(statuses, result.Status) &&
+		(r.Ports == nil || r.Ports.Contains(result.Port)) &&
+		r.matchesHost(result.Host)
+}
+
+// matchesEvent reports whether the rule applies to a change found by watch.
+func (r *Rule) matchesEvent(e watch.Event) bool {
+	events := r.Events
+	if len(events) == 0 {
+		events = []watch.EventType{watch.PortOpened}
+	}
+	return slices.Contains(events, e.Type) &&
+		(r.Ports == nil || r.Ports.Contains(e.Port)) &&
+		r.matchesHost(e.Host)
+}
+
+// notifierTarget is a configured notifier and the findings to send it.
+type notifierTarget struct {
+	name     string
+	notifier Notifier
+	findings []Finding
+}
+
+// Dispatcher sends notifications about the results and changes that match
+// its rules. It is an OutputWriter, so it can be given the results of a
+// scan and send notifications when it ends.
+type Dispatcher struct {
+	rules   []Rule
+	targets []*notifierTarget
+	run     *output.ScanRun
+}
+
+// NewDispatcher creates the notifiers in cfg and checks its rules.
+func NewDispatcher(cfg *Config) (*Dispatcher, error) {
+	d := &Dispatcher{}
+	names := map[string]bool{}
+	for i, nc := range cfg.Notifiers {
+		if nc.Name == "" {
+			nc.Name = fmt.Sprintf("notifier %d", i+1)
+		}
+		if names[nc.Name] {
+			return nil, fmt.Errorf("duplicate notifier: %s", nc.Name)
+		}
+		names[nc.Name] = true
+
+		notifier, err := newNotifier(nc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", nc.Name, err)
+		}
+		d.targets = append(d.targets, &notifierTarget{name: nc.Name, notifier: notifier})
+	}
+
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		for _, h := range rule.Hosts {
+			prefix, err := iputil.ParsePrefix(h)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Name, err)
+			}
+			rule.prefixes = append(rule.prefixes, prefix)
+		}
+		for _, name := range rule.Notify {
+			if !names[name] {
+				return nil, fmt.Errorf("%s: unknown notifier: %s", rule.Name, name)
+			}
+		}
+		d.rules = append(d.rules, rule)
+	}
+
+	return d, nil
+}
+
+// newNotifier creates a Notifier from its config.
+func newNotifier(nc NotifierConfig) (Notifier, error) {
+	url := os.ExpandEnv(nc.URL)
+	if url == "" {
+		return nil, errors.New("url is empty")
+	}
+
+	var w *Webhook
+	switch nc.Type {
+	case "webhook", "":
+		w = NewWebhook(url)
+	case "slack":
+		w = NewSlack(url)
+	case "teams":
+		w = NewTeams(url)
+	default:
+		return nil, fmt.Errorf("unknown notifier type: %s", nc.Type)
+	}
+
+	if nc.Template != "" {
+		if nc.Type != "webhook" && nc.Type != "" {
+			return nil, errors.New("template is only supported by webhook notifiers")
+		}
+		if err := w.SetTemplate(nc.Template); err != nil {
+			return nil, err
+		}
+	}
+	if nc.Secret != "" {
+		w.SetSecret(os.ExpandEnv(nc.Secret))
+	}
+	for name, value := range nc.Headers {
+		w.SetHeader(name, os.ExpandEnv(value))
+	}
+	if nc.Retries != nil {
+		w.SetRetries(*nc.Retries)
+	}
+	return w, nil
+}
+
+// add records a finding of rule for each notifier the rule sends to.
+func (d *Dispatcher) add(rule *Rule, f Finding) {
+	for _, t := range d.targets {
+		if len(rule.Notify) == 0 || slices.Contains(rule.Notify, t.name) {
+			t.findings = append(t.findings, f)
+		}
+	}
+}
+
+// Begin starts collecting findings for a scan.
+func (d *Dispatcher) Begin(run *output.ScanRun) error {
+	d.run = run
+	return nil
+}
+
+// WriteResult records a finding for every rule that matches the result.
+func (d *Dispatcher) WriteResult(r output.Result) error {
+	for i := range d.rules {
+		rule := &d.rules[i]
+		if rule.matchesResult(r) {
+			d.add(rule, Finding{
+				Rule:    rule.Name,
+				Host:    r.Host,
+				Port:    r.Port,
+				Status:  &r.Status,
+				Service: r.Service,
+				Banner:  r.Banner,
+			})
+		}
+	}
+	return nil
+}
+
+// End sends the scan's findings.
+func (d *Dispatcher) End(run *output.ScanRun) error {
+	return d.NotifyScan(context.Background(), run)
+}
+
+// NotifyScan sends the findings of a scan that has ended. It stops retrying
+// once ctx is done.
+func (d *Dispatcher) NotifyScan(ctx context.Context, run *output.ScanRun) error {
+	return d.send(ctx, run.Targets, "finding(s) in a scan of")
+}
+
+// NotifyEvents sends notifications for the changes found by a watch cycle
+// that match the rules.
+func (d *Dispatcher) NotifyEvents(ctx context.Context, targets []string, events []watch.Event) error {
+	for _, e := range events {
+		for i := range d.rules {
+			rule := &d.rules[i]
+			if !rule.matchesEvent(e) {
+				continue
+			}
+
+			f := Finding{Rule: rule.Name, Host: e.Host, Port: e.Port, Event: e.Type}
+			if state := cmp.Or(e.After, e.Before); state != nil {
+				f.Status = &state.Status
+				f.Service = state.Service
+				f.Banner = state.Banner
+			}
+			d.add(rule, f)
+		}
+	}
+	return d.send(ctx, targets, "change(s) in")
+}
+
+// send sends each notifier its findings, if it has any, and clears them.
+func (d *Dispatcher) send(ctx context.Context, targets []string, what string) error {
+	var errs []error
+	for _, t := range d.targets {
+		if len(t.findings) == 0 {
+			continue
+		}
+		slices.SortStableFunc(t.findings, func(a, b Finding) int {
+			return cmp.Or(output.CompareHosts(a.Host, b.Host), cmp.Compare(a.Port, b.Port))
+		})
+
+		n := &Notification{
+			Title:    fmt.Sprintf("network-scanner: %d %s %s", len(t.findings), what, strings.Join(targets, ", ")),
+			Time:     time.Now(),
+			Targets:  targets,
+			Findings: t.findings,
+		}
+		if err := t.notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("notifying %s: %w", t.name, err))
+		}
+		t.findings = nil
+	}
+	return errors.Join(errs...)
+}
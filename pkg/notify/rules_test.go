@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/diff"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadConfig(t *testing.T, text string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(text), 0o644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestDispatcher(t *testing.T) {
+	ops := newReceiver(t)
+	security := newReceiver(t)
+	t.Setenv("TEST_SECURITY_URL", security.URL)
+
+	cfg := loadConfig(t, `
+notifiers:
+  - name: ops
+    url: `+ops.URL+`
+  - name: security
+    type: slack
+    url: ${TEST_SECURITY_URL}
+rules:
+  - name: remote access
+    ports: [22, 3389]
+    hosts: [10.0.0.0/24]
+    notify: [security]
+  - name: timeouts
+    statuses: [timeout]
+`)
+	d, err := NewDispatcher(cfg)
+	require.NoError(t, err)
+
+	ch := make(chan scanner.Port, 5)
+	ch <- scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open}
+	ch <- scanner.Port{Host: "10.0.0.1", Port: 80, Status: scanner.Open}
+	ch <- scanner.Port{Host: "10.0.0.2", Port: 3389, Status: scanner.Closed}
+	ch <- scanner.Port{Host: "10.0.1.1", Port: 3389, Status: scanner.Open}
+	ch <- scanner.Port{Host: "10.0.0.3", Port: 443, Status: scanner.Timeout}
+	close(ch)
+	require.NoError(t, output.Stream(d, &output.ScanRun{Targets: []string{"10.0.0.0/16"}}, ch, nil))
+
+	require.Len(t, security.bodies, 1)
+	assert.Contains(t, string(security.bodies[0]), "10.0.0.1:22")
+	assert.NotContains(t, string(security.bodies[0]), "10.0.1.1")
+
+	require.Len(t, ops.bodies, 1)
+	var n Notification
+	require.NoError(t, json.Unmarshal(ops.bodies[0], &n))
+	assert.Equal(t, "network-scanner: 1 finding(s) in a scan of 10.0.0.0/16", n.Title)
+	require.Len(t, n.Findings, 1)
+	assert.Equal(t, "timeouts", n.Findings[0].Rule)
+	assert.Equal(t, 443, n.Findings[0].Port)
+}
+
+func TestDispatcher_NotifyEvents(t *testing.T) {
+	r := newReceiver(t)
+	cfg := loadConfig(t, `
+notifiers:
+  - url: `+r.URL+`
+rules:
+  - name: opened
+  - name: vanished
+    events: [host_vanished]
+`)
+	d, err := NewDispatcher(cfg)
+	require.NoError(t, err)
+
+	at := time.Now()
+	open := &diff.PortState{Status: scanner.Open, Service: "ssh"}
+	require.NoError(t, d.NotifyEvents(context.Background(), []string{"10.0.0.0/24"}, []watch.Event{
+		{Time: at, Type: watch.PortOpened, Host: "10.0.0.1", Port: 22, After: open},
+		{Time: at, Type: watch.PortClosed, Host: "10.0.0.1", Port: 80},
+		{Time: at, Type: watch.HostVanished, Host: "10.0.0.2"},
+	}))
+
+	require.Len(t, r.bodies, 1)
+	var n Notification
+	require.NoError(t, json.Unmarshal(r.bodies[0], &n))
+	assert.Equal(t, "network-scanner: 2 change(s) in 10.0.0.0/24", n.Title)
+	require.Len(t, n.Findings, 2)
+	assert.Equal(t, "ssh", n.Findings[0].Service)
+	assert.Equal(t, watch.HostVanished, n.Findings[1].Event)
+	assert.Nil(t, n.Findings[1].Status)
+
+	// Nothing is sent when nothing matches.
+	require.NoError(t, d.NotifyEvents(context.Background(), nil, nil))
+	assert.Len(t, r.bodies, 1)
+}
+
+func TestNewDispatcher_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"unknown type", "notifiers: [{url: http://x, type: email}]", "notifier 1: unknown notifier type: email"},
+		{"no url", "notifiers: [{name: ops}]", "ops: url is empty"},
+		{"duplicate", "notifiers: [{name: a, url: http://x}, {name: a, url: http://y}]", "duplicate notifier: a"},
+		{"slack template", "notifiers: [{type: slack, url: http://x, template: x}]", "notifier 1: template is only supported by webhook notifiers"},
+		{"unknown notifier", "rules: [{notify: [pager]}]", "rule 1: unknown notifier: pager"},
+		{"bad host", "rules: [{hosts: [10.0.0.0/40]}]", "rule 1: invalid CIDR: 10.0.0.0/40"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDispatcher(loadConfig(t, tc.config))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
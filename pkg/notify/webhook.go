@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+)
+
+const (
+	// DefaultRetries is how many times a failed webhook request is retried.
+	DefaultRetries = 3
+	// defaultBackoff is the wait before the first retry. It doubles after
+	// each retry.
+	defaultBackoff = time.Second
+	// requestTimeout bounds each webhook request.
+	requestTimeout = 10 * time.Second
+	// SignatureHeader carries the HMAC-SHA256 signature of the body when a
+	// secret is set, as "sha256=" followed by the hex digest.
+	SignatureHeader = "X-Signature-256"
+)
+
+// Encoder turns a notification into a request body.
+type Encoder func(n *Notification) ([]byte, error)
+
+// Webhook is a Notifier that POSTs notifications to a URL.
+type Webhook struct {
+	url     string
+	encode  Encoder
+	headers map[string]string
+	secret  []byte
+	retries int
+	backoff time.Duration
+	client  *http.Client
+}
+
+// NewWebhook creates a new Webhook that sends notifications as JSON.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:     url,
+		encode:  encodeJSON,
+		headers: map[string]string{"Content-Type": "application/json"},
+		retries: DefaultRetries,
+		backoff: defaultBackoff,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// SetEncoder sets how notifications are turned into request bodies.
+func (w *Webhook) SetEncoder(encode Encoder) {
+	w.encode = encode
+}
+
+// SetTemplate sends the output of a text/template executed with the
+// Notification as the body instead of the default JSON. The same helper
+// functions as --template are available.
+func (w *Webhook) SetTemplate(text string) error {
+	tmpl, err := template.New("payload").Funcs(output.TemplateFuncs()).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	w.encode = func(n *Notification) ([]byte, error) {
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, n)
+		return buf.Bytes(), err
+	}
+	return nil
+}
+
+// SetHeader sets a header sent with every request.
+func (w *Webhook) SetHeader(name, value string) {
+	w.headers[name] = value
+}
+
+// SetSecret signs every request body with HMAC-SHA256 using secret, so the
+// receiver can check where it came from. The signature is sent in the
+// SignatureHeader header.
+func (w *Webhook) SetSecret(secret string) {
+	w.secret = []byte(secret)
+}
+
+// SetRetries sets how many times a request is retried after a network error
+// or a 429 or 5xx response.
+func (w *Webhook) SetRetries(retries int) {
+	w.retries = retries
+}
+
+// SetBackoff sets the wait before the first retry. It doubles after each
+// retry.
+func (w *Webhook) SetBackoff(backoff time.Duration) {
+	w.backoff = backoff
+}
+
+// Notify sends a notification, retrying if it fails.
+func (w *Webhook) Notify(ctx context.Context, n *Notification) error {
+	body, err := w.encode(n)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.send(ctx, body)
+		if err == nil || !retry || attempt == w.retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes a single request and reports whether it is worth retrying if
+// it failed.
+func (w *Webhook) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+	if w.secret != nil {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// encodeJSON encodes a notification as a JSON document.
+func encodeJSON(n *Notification) ([]byte, error) {
+	return json.Marshal(n)
+}
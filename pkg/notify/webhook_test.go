@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local webhook receiver that records requests and replies
+// with the given status codes in turn, then 200.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		if len(r.statuses) > 0 {
+			w.WriteHeader(r.statuses[0])
+			r.statuses = r.statuses[1:]
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func testNotification() *Notification {
+	open := scanner.Open
+	return &Notification{
+		Title:   "network-scanner: 1 finding(s) in a scan of 10.0.0.0/24",
+		Time:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Targets: []string{"10.0.0.0/24"},
+		Findings: []Finding{
+			{Rule: "rdp", Host: "10.0.0.5", Port: 3389, Status: &open, Service: "ms-wbt-server"},
+		},
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	r := newReceiver(t)
+	w := NewWebhook(r.URL)
+	w.SetHeader("Authorization", "Bearer token")
+	require.NoError(t, w.Notify(context.Background(), testNotification()))
+
+	require.Len(t, r.requests, 1)
+	assert.Equal(t, http.MethodPost, r.requests[0].Method)
+	assert.Equal(t, "application/json", r.requests[0].Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", r.requests[0].Header.Get("Authorization"))
+	assert.Empty(t, r.requests[0].Header.Get(SignatureHeader))
+	assert.JSONEq(t, `{
+		"title": "network-scanner: 1 finding(s) in a scan of 10.0.0.0/24",
+		"time": "2024-01-01T00:00:00Z",
+		"targets": ["10.0.0.0/24"],
+		"findings": [{"rule": "rdp", "host": "10.0.0.5", "port": 3389, "status": "open", "service": "ms-wbt-server"}]
+	}`, string(r.bodies[0]))
+}
+
+func TestWebhook_Secret(t *testing.T) {
+	r := newReceiver(t)
+	w := NewWebhook(r.URL)
+	w.SetSecret("s3cret")
+	require.NoError(t, w.Notify(context.Background(), testNotification()))
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(r.bodies[0])
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.requests[0].Header.Get(SignatureHeader))
+}
+
+func TestWebhook_Template(t *testing.T) {
+	r := newReceiver(t)
+	w := NewWebhook(r.URL)
+	require.NoError(t, w.SetTemplate(`{"summary": {{json .Title}}, "hosts": [{{range $i, $f := .Findings}}{{if $i}},{{end}}{{json $f.Host}}{{end}}]}`))
+	require.NoError(t, w.Notify(context.Background(), testNotification()))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(r.bodies[0], &body))
+	assert.Equal(t, []any{"10.0.0.5"}, body["hosts"])
+
+	assert.Error(t, w.SetTemplate("{{.Title"))
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		statuses []int
+		retries  int
+		requests int
+		err      string
+	}{
+		{"recovers", []int{500, 429}, 3, 3, ""},
+		{"gives up", []int{503, 503, 503}, 2, 3, "webhook returned 503 Service Unavailable"},
+		{"client error", []int{400}, 3, 1, "webhook returned 400 Bad Request"},
+		{"no retries", []int{500}, 0, 1, "webhook returned 500 Internal Server Error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newReceiver(t, tc.statuses...)
+			w := NewWebhook(r.URL)
+			w.SetRetries(tc.retries)
+			w.SetBackoff(time.Millisecond)
+
+			err := w.Notify(context.Background(), testNotification())
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+			assert.Len(t, r.requests, tc.requests)
+		})
+	}
+}
+
+func TestWebhook_Cancel(t *testing.T) {
+	r := newReceiver(t, 500, 500)
+	w := NewWebhook(r.URL)
+	w.SetBackoff(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.Notify(ctx, testNotification()), context.DeadlineExceeded)
+	assert.Len(t, r.requests, 1)
+}
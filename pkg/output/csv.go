@@ -3,8 +3,12 @@ package output
 import (
 	"encoding/csv"
 	"io"
+	"strconv"
 )
 
+// csvHeaders are the columns written by CsvWriter.
+var csvHeaders = []string{"IP Address", "Port", "Status", "Latency (ms)", "Banner"}
+
 // CsvWriter writes data in a CSV format.
 type CsvWriter struct {
 	writer  *csv.Writer
@@ -12,20 +16,40 @@ type CsvWriter struct {
 }
 
 // NewCsvWriter creates a new CsvWriter.
-func NewCsvWriter(writer io.Writer, headers []string) *CsvWriter {
+func NewCsvWriter(writer io.Writer) *CsvWriter {
 	return &CsvWriter{
 		writer:  csv.NewWriter(writer),
-		headers: headers,
+		headers: csvHeaders,
 	}
 }
 
-// PrintHeader writes the headers to the CSV file.
-func (c *CsvWriter) PrintHeader() {
-	c.writer.Write(c.headers)
+// Begin writes the headers to the CSV file.
+func (c *CsvWriter) Begin(run *ScanRun) error {
+	return c.writeRecord(c.headers)
+}
+
+// WriteResult writes a single row to the CSV file.
+func (c *CsvWriter) WriteResult(r Result) error {
+	return c.writeRecord([]string{
+		r.Host,
+		strconv.Itoa(r.Port),
+		r.Status.String(),
+		strconv.FormatFloat(milliseconds(r.Latency), 'f', 3, 64),
+		r.Banner,
+	})
 }
 
-// PrintRow writes a single row to the CSV file.
-func (c *CsvWriter) PrintRow(row []string) {
-	c.writer.Write(row)
+// End is a no-op; every record is flushed as it is written.
+func (c *CsvWriter) End(run *ScanRun) error {
+	return nil
+}
+
+// writeRecord writes and flushes a single record so rows appear as soon as
+// they are scanned.
+func (c *CsvWriter) writeRecord(record []string) error {
+	if err := c.writer.Write(record); err != nil {
+		return err
+	}
 	c.writer.Flush()
+	return c.writer.Error()
 }
@@ -2,13 +2,16 @@ package output
 
 import (
 	"bytes"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
 )
 
 func TestNewCsvWriter(t *testing.T) {
-	headers := []string{"h1", "h2", "h3"}
 	writer := &bytes.Buffer{}
-	csvWriter := NewCsvWriter(writer, headers)
+	csvWriter := NewCsvWriter(writer)
 
 	if csvWriter == nil {
 		t.Error("NewCsvWriter should not return nil")
@@ -19,55 +22,68 @@ func TestNewCsvWriter(t *testing.T) {
 		t.Error("csvWriter.writer should not be nil")
 	}
 
-	if len(csvWriter.headers) != len(headers) {
-		t.Errorf("expected headers length %d, got %d", len(headers), len(csvWriter.headers))
-	}
-
-	for i := range headers {
-		if csvWriter.headers[i] != headers[i] {
-			t.Errorf("expected header %s, got %s", headers[i], csvWriter.headers[i])
-		}
+	if len(csvWriter.headers) != len(csvHeaders) {
+		t.Errorf("expected headers length %d, got %d", len(csvHeaders), len(csvWriter.headers))
 	}
 }
 
-func TestCsvWriter_PrintHeader(t *testing.T) {
-	headers := []string{"col1", "col2", "col3"}
+func TestCsvWriter_Begin(t *testing.T) {
 	writer := &bytes.Buffer{}
-	csvWriter := NewCsvWriter(writer, headers)
+	csvWriter := NewCsvWriter(writer)
 
-	csvWriter.PrintHeader()
-	csvWriter.writer.Flush()
+	if err := csvWriter.Begin(&ScanRun{}); err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
 
-	expected := "col1,col2,col3\n"
+	expected := "IP Address,Port,Status,Latency (ms),Banner\n"
 	if writer.String() != expected {
 		t.Errorf("expected %q, got %q", expected, writer.String())
 	}
 }
 
-func TestCsvWriter_PrintRow(t *testing.T) {
-	row := []string{"d1", "d2", "d3"}
+func TestCsvWriter_WriteResult(t *testing.T) {
 	writer := &bytes.Buffer{}
-	csvWriter := NewCsvWriter(writer, []string{})
+	csvWriter := NewCsvWriter(writer)
 
-	csvWriter.PrintRow(row)
+	r := Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: 1500 * time.Microsecond, Banner: "SSH-2.0, hi"}
+	if err := csvWriter.WriteResult(r); err != nil {
+		t.Fatalf("WriteResult returned error: %v", err)
+	}
 
-	expected := "d1,d2,d3\n"
+	expected := "10.0.0.1,22,Open,1.500,\"SSH-2.0, hi\"\n"
 	if writer.String() != expected {
 		t.Errorf("expected %q, got %q", expected, writer.String())
 	}
 }
 
-func TestCsvWriter_PrintHeaderAndRow(t *testing.T) {
-	headers := []string{"h1", "h2"}
-	row := []string{"r1", "r2"}
+func TestCsvWriter_BeginAndWriteResult(t *testing.T) {
 	writer := &bytes.Buffer{}
-	csvWriter := NewCsvWriter(writer, headers)
+	csvWriter := NewCsvWriter(writer)
+	run := &ScanRun{}
 
-	csvWriter.PrintHeader()
-	csvWriter.PrintRow(row)
+	csvWriter.Begin(run)
+	csvWriter.WriteResult(Result{Host: "10.0.0.1", Port: 80, Status: scanner.Timeout})
+	csvWriter.End(run)
 
-	expected := "h1,h2\nr1,r2\n"
+	expected := "IP Address,Port,Status,Latency (ms),Banner\n10.0.0.1,80,Timed Out,0.000,\n"
 	if writer.String() != expected {
 		t.Errorf("expected %q, got %q", expected, writer.String())
 	}
 }
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestCsvWriter_WriteError(t *testing.T) {
+	csvWriter := NewCsvWriter(failingWriter{})
+
+	if err := csvWriter.Begin(&ScanRun{}); err == nil {
+		t.Error("expected Begin to return the underlying write error")
+	}
+	if err := csvWriter.WriteResult(Result{Host: "10.0.0.1", Port: 80}); err == nil {
+		t.Error("expected WriteResult to return the underlying write error")
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GrepableWriter writes one line per host listing all of its ports, in the
+// style of nmap's grepable output.
+type GrepableWriter struct {
+	writer io.Writer
+	hosts  *hostGroups
+}
+
+// NewGrepableWriter creates a new GrepableWriter.
+func NewGrepableWriter(writer io.Writer) *GrepableWriter {
+	return &GrepableWriter{
+		writer: writer,
+		hosts:  newHostGroups(),
+	}
+}
+
+// Begin writes a comment describing the scan.
+func (g *GrepableWriter) Begin(run *ScanRun) error {
+	g.hosts = newHostGroups()
+	_, err := fmt.Fprintf(g.writer, "# network-scanner scan initiated %s as: %s\n", run.Start.Format(nmapTimeFormat), run.Command)
+	return err
+}
+
+// WriteResult buffers a single result until its host is complete.
+func (g *GrepableWriter) WriteResult(r Result) error {
+	g.hosts.add(r)
+	return nil
+}
+
+// EndHost writes the line for a host whose ports have all been scanned.
+func (g *GrepableWriter) EndHost(host string) error {
+	results := g.hosts.take(host)
+	if len(results) == 0 {
+		return nil
+	}
+
+	entries := make([]string, len(results))
+	for i, r := range results {
+		state, _ := nmapPortState(r.Status)
+		entries[i] = fmt.Sprintf("%d/%s/tcp//%s//%s/", r.Port, state, grepableField(r.Service), grepableField(r.Banner))
+	}
+
+	_, err := fmt.Fprintf(g.writer, "Host: %s ()\tPorts: %s\n", host, strings.Join(entries, ", "))
+	return err
+}
+
+// End writes any hosts that are still buffered, followed by a summary
+// comment.
+func (g *GrepableWriter) End(run *ScanRun) error {
+	for _, host := range g.hosts.hosts() {
+		if err := g.EndHost(host); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(g.writer, "# network-scanner done at %s -- %d IP addresses scanned in %.2f seconds\n",
+		run.End.Format(nmapTimeFormat), run.HostCount, run.Elapsed().Seconds())
+	return err
+}
+
+// grepableField replaces the characters that separate grepable fields.
+func grepableField(s string) string {
+	return strings.NewReplacer("/", "|", ",", ";", "\t", " ").Replace(s)
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestGrepableWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	gw := NewGrepableWriter(writer)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{Command: "network-scanner 10.0.0.0/29 22,80", HostCount: 6, Start: start}
+
+	require.NoError(t, gw.Begin(run))
+	require.NoError(t, gw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, gw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.6", Port: 22, Status: scanner.Timeout})))
+	require.NoError(t, gw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open, Banner: "SSH-2.0-OpenSSH_9.6/Ubuntu, x"})))
+	require.NoError(t, gw.EndHost("10.0.0.5"))
+	run.End = start.Add(2 * time.Second)
+	require.NoError(t, gw.End(run))
+
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "# network-scanner scan initiated Tue Jan 2 03:04:05 2024 as: network-scanner 10.0.0.0/29 22,80", lines[0])
+	assert.Equal(t, "Host: 10.0.0.5 ()\tPorts: 22/open/tcp//ssh//SSH-2.0-OpenSSH_9.6|Ubuntu; x/, 80/closed/tcp//http///", lines[1])
+	assert.Equal(t, "Host: 10.0.0.6 ()\tPorts: 22/filtered/tcp//ssh///", lines[2])
+	assert.Equal(t, "# network-scanner done at Tue Jan 2 03:04:07 2024 -- 6 IP addresses scanned in 2.00 seconds", lines[3])
+}
+
+func TestGrepableWriter_EndHostWithoutResults(t *testing.T) {
+	writer := &bytes.Buffer{}
+	gw := NewGrepableWriter(writer)
+
+	require.NoError(t, gw.EndHost("10.0.0.1"))
+	assert.Empty(t, writer.String())
+}
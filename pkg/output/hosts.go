@@ -0,0 +1,56 @@
+package output
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// hostGroups collects results by host.
+type hostGroups struct {
+	results map[string][]Result
+}
+
+func newHostGroups() *hostGroups {
+	return &hostGroups{results: make(map[string][]Result)}
+}
+
+// add appends a result to its host's group.
+func (g *hostGroups) add(r Result) {
+	g.results[r.Host] = append(g.results[r.Host], r)
+}
+
+// hosts returns the hosts seen so far in numeric address order.
+func (g *hostGroups) hosts() []string {
+	hosts := make([]string, 0, len(g.results))
+	for host := range g.results {
+		hosts = append(hosts, host)
+	}
+	slices.SortFunc(hosts, CompareHosts)
+	return hosts
+}
+
+// take removes a host's group and returns its results in port order.
+func (g *hostGroups) take(host string) []Result {
+	results := g.results[host]
+	delete(g.results, host)
+	slices.SortFunc(results, func(a, b Result) int { return a.Port - b.Port })
+	return results
+}
+
+// CompareHosts orders IP addresses numerically, with IPv4 before IPv6.
+// Hosts that are not IP addresses sort after all addresses, lexically.
+func CompareHosts(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA == nil && errB == nil:
+		return addrA.Unmap().Compare(addrB.Unmap())
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
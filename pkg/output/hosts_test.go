@@ -0,0 +1,28 @@
+package output
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareHosts(t *testing.T) {
+	hosts := []string{"example.com", "2001:db8::1", "10.0.0.10", "10.0.0.9", "9.255.255.255", "::ffff:10.0.0.1"}
+	slices.SortFunc(hosts, CompareHosts)
+
+	assert.Equal(t, []string{"9.255.255.255", "::ffff:10.0.0.1", "10.0.0.9", "10.0.0.10", "2001:db8::1", "example.com"}, hosts)
+}
+
+func TestHostGroups(t *testing.T) {
+	g := newHostGroups()
+	g.add(Result{Host: "10.0.0.2", Port: 443})
+	g.add(Result{Host: "10.0.0.1", Port: 80})
+	g.add(Result{Host: "10.0.0.2", Port: 22})
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, g.hosts())
+
+	results := g.take("10.0.0.2")
+	assert.Equal(t, []Result{{Host: "10.0.0.2", Port: 22}, {Host: "10.0.0.2", Port: 443}}, results)
+	assert.Equal(t, []string{"10.0.0.1"}, g.hosts())
+}
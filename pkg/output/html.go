@@ -0,0 +1,118 @@
+package output
+
+import (
+	"cmp"
+	_ "embed"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/services"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlReportTemplate string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"ms":   milliseconds,
+	"statusKey": func(s scanner.Status) string {
+		key, _ := s.MarshalText()
+		return string(key)
+	},
+}).Parse(htmlReportTemplate))
+
+// htmlCount is a labelled count in the report summary.
+type htmlCount struct {
+	Key   string
+	Label string
+	Count int
+}
+
+// htmlHost is a single host section of the report.
+type htmlHost struct {
+	Host    string
+	Open    int
+	Results []Result
+}
+
+// htmlReport is the data passed to the report template.
+type htmlReport struct {
+	Run          *ScanRun
+	Elapsed      time.Duration
+	StatusCounts []htmlCount
+	PortCounts   []htmlCount
+	Hosts        []htmlHost
+}
+
+// HtmlWriter writes a self-contained HTML report once the scan has finished.
+type HtmlWriter struct {
+	writer io.Writer
+	hosts  *hostGroups
+}
+
+// NewHtmlWriter creates a new HtmlWriter.
+func NewHtmlWriter(writer io.Writer) *HtmlWriter {
+	return &HtmlWriter{
+		writer: writer,
+		hosts:  newHostGroups(),
+	}
+}
+
+// Begin starts a new report.
+func (h *HtmlWriter) Begin(run *ScanRun) error {
+	h.hosts = newHostGroups()
+	return nil
+}
+
+// WriteResult buffers a single result under its host.
+func (h *HtmlWriter) WriteResult(r Result) error {
+	h.hosts.add(r)
+	return nil
+}
+
+// End renders the report.
+func (h *HtmlWriter) End(run *ScanRun) error {
+	report := htmlReport{
+		Run:     run,
+		Elapsed: run.Elapsed().Round(time.Millisecond),
+	}
+
+	counts := make(map[scanner.Status]int)
+	openPorts := make(map[int]int)
+	for _, host := range h.hosts.hosts() {
+		section := htmlHost{Host: host, Results: h.hosts.take(host)}
+		for _, r := range section.Results {
+			counts[r.Status]++
+			if r.Status == scanner.Open {
+				section.Open++
+				openPorts[r.Port]++
+			}
+		}
+		report.Hosts = append(report.Hosts, section)
+	}
+
+	counts = statusCounts(run, counts)
+	for _, status := range []scanner.Status{scanner.Open, scanner.Closed, scanner.Timeout} {
+		key, _ := status.MarshalText()
+		report.StatusCounts = append(report.StatusCounts, htmlCount{Key: string(key), Label: status.String(), Count: counts[status]})
+	}
+
+	for port, count := range openPorts {
+		report.PortCounts = append(report.PortCounts, htmlCount{Key: strconv.Itoa(port), Label: services.Lookup(port), Count: count})
+	}
+	slices.SortFunc(report.PortCounts, func(a, b htmlCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		portA, _ := strconv.Atoi(a.Key)
+		portB, _ := strconv.Atoi(b.Key)
+		return cmp.Compare(portA, portB)
+	})
+
+	return htmlTemplate.Execute(h.writer, report)
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+func TestHtmlWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	hw := NewHtmlWriter(writer)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{Targets: []string{"10.0.0.0/29"}, Ports: "22,80", Start: start}
+
+	require.NoError(t, hw.Begin(run))
+	require.NoError(t, hw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open, Banner: "<script>alert(1)</script>"})))
+	require.NoError(t, hw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, hw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Open})))
+	run.End = start.Add(1234 * time.Millisecond)
+	require.NoError(t, hw.End(run))
+
+	out := writer.String()
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "Targets: 10.0.0.0/29")
+	assert.Contains(t, out, "Took 1.234s")
+	assert.Contains(t, out, "<h2>10.0.0.2 <small>(1 open)</small></h2>")
+	assert.Contains(t, out, "<h2>10.0.0.5 <small>(1 open)</small></h2>")
+	assert.Less(t, strings.Index(out, "<h2>10.0.0.2"), strings.Index(out, "<h2>10.0.0.5"))
+	assert.Contains(t, out, `<tr><td class="status-open">Open</td><td class="num">2</td></tr>`)
+	assert.Contains(t, out, `<tr><td class="status-closed">Closed</td><td class="num">1</td></tr>`)
+	assert.Contains(t, out, `<tr><td class="num">22</td><td>ssh</td><td class="num">2</td></tr>`)
+	assert.Contains(t, out, `<tr data-status="closed">`)
+	assert.Contains(t, out, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, out, "<script>alert(1)</script>")
+	assert.NotContains(t, out, "http://")
+	assert.NotContains(t, out, "https://")
+}
+
+func TestHtmlWriter_SummaryCounts(t *testing.T) {
+	writer := &bytes.Buffer{}
+	hw := NewHtmlWriter(writer)
+	run := &ScanRun{Summary: &stats.Summary{Hosts: 4, Open: 1, Closed: 7, Timeout: 2}}
+
+	require.NoError(t, hw.Begin(run))
+	require.NoError(t, hw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open})))
+	require.NoError(t, hw.End(run))
+
+	out := writer.String()
+	assert.Contains(t, out, `<tr><td class="status-closed">Closed</td><td class="num">7</td></tr>`, "filtered results are counted")
+	assert.Contains(t, out, `<tr><td class="status-timeout">Timed Out</td><td class="num">2</td></tr>`)
+}
+
+func TestHtmlWriter_NoResults(t *testing.T) {
+	writer := &bytes.Buffer{}
+	hw := NewHtmlWriter(writer)
+	run := &ScanRun{}
+
+	require.NoError(t, hw.Begin(run))
+	require.NoError(t, hw.End(run))
+
+	assert.Contains(t, writer.String(), "<p>No results.</p>")
+}
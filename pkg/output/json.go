@@ -0,0 +1,141 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+// jsonResult is the JSON encoding of a Result.
+type jsonResult struct {
+	Host      string         `json:"host"`
+	Port      int            `json:"port"`
+	Protocol  string         `json:"protocol"`
+	Status    scanner.Status `json:"status"`
+	LatencyMs float64        `json:"latency_ms"`
+	Service   string         `json:"service,omitempty"`
+	Banner    string         `json:"banner,omitempty"`
+}
+
+// jsonRun is the JSON encoding of a ScanRun.
+type jsonRun struct {
+	Targets   []string     `json:"targets"`
+	Ports     string       `json:"ports"`
+	TimeoutMs float64      `json:"timeout_ms"`
+	Start     time.Time    `json:"start"`
+	End       time.Time    `json:"end"`
+	ElapsedMs float64      `json:"elapsed_ms"`
+	Summary   *jsonSummary `json:"summary,omitempty"`
+}
+
+// jsonSummary is the JSON encoding of a stats.Summary.
+type jsonSummary struct {
+	Hosts         int               `json:"hosts"`
+	HostsWithOpen int               `json:"hosts_with_open"`
+	Open          int               `json:"open"`
+	Closed        int               `json:"closed"`
+	Timeout       int               `json:"timeout"`
+	TopPorts      []stats.PortCount `json:"top_ports"`
+	LatencyP50Ms  float64           `json:"latency_p50_ms"`
+	LatencyP95Ms  float64           `json:"latency_p95_ms"`
+}
+
+// jsonDocument is the top level JSON document written by JsonWriter.
+type jsonDocument struct {
+	Scan    jsonRun      `json:"scan"`
+	Results []jsonResult `json:"results"`
+}
+
+func newJsonResult(r Result) jsonResult {
+	return jsonResult{
+		Host:      r.Host,
+		Port:      r.Port,
+		Protocol:  "tcp",
+		Status:    r.Status,
+		LatencyMs: milliseconds(r.Latency),
+		Service:   r.Service,
+		Banner:    r.Banner,
+	}
+}
+
+func newJsonRun(run *ScanRun) jsonRun {
+	r := jsonRun{
+		Targets:   run.Targets,
+		Ports:     run.Ports,
+		TimeoutMs: milliseconds(run.Timeout),
+		Start:     run.Start,
+		End:       run.End,
+		ElapsedMs: milliseconds(run.Elapsed()),
+	}
+	if s := run.Summary; s != nil {
+		r.Summary = &jsonSummary{
+			Hosts:         s.Hosts,
+			HostsWithOpen: s.HostsWithOpen,
+			Open:          s.Open,
+			Closed:        s.Closed,
+			Timeout:       s.Timeout,
+			TopPorts:      s.TopPorts,
+			LatencyP50Ms:  milliseconds(s.LatencyP50),
+			LatencyP95Ms:  milliseconds(s.LatencyP95),
+		}
+		if r.Summary.TopPorts == nil {
+			r.Summary.TopPorts = []stats.PortCount{}
+		}
+	}
+	return r
+}
+
+// MarshalJSON encodes the result the same way as the JSON output formats.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJsonResult(r))
+}
+
+// MarshalJSON encodes the run the same way as the JSON output format.
+func (run ScanRun) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJsonRun(&run))
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// JsonWriter writes the scan metadata and all results as a single JSON
+// document once the scan has finished.
+type JsonWriter struct {
+	writer  io.Writer
+	results []jsonResult
+}
+
+// NewJsonWriter creates a new JsonWriter.
+func NewJsonWriter(writer io.Writer) *JsonWriter {
+	return &JsonWriter{
+		writer:  writer,
+		results: []jsonResult{},
+	}
+}
+
+// Begin starts a new document.
+func (j *JsonWriter) Begin(run *ScanRun) error {
+	j.results = []jsonResult{}
+	return nil
+}
+
+// WriteResult buffers a single result.
+func (j *JsonWriter) WriteResult(r Result) error {
+	j.results = append(j.results, newJsonResult(r))
+	return nil
+}
+
+// End writes the complete document.
+func (j *JsonWriter) End(run *ScanRun) error {
+	encoder := json.NewEncoder(j.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonDocument{
+		Scan:    newJsonRun(run),
+		Results: j.results,
+	})
+}
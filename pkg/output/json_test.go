@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+func TestJsonWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	jw := NewJsonWriter(writer)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{
+		Targets: []string{"10.0.0.0/30"},
+		Ports:   "22,80",
+		Timeout: time.Second,
+		Start:   start,
+	}
+
+	require.NoError(t, jw.Begin(run))
+	require.NoError(t, jw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: 1500 * time.Microsecond, Banner: "SSH-2.0"}))
+	require.NoError(t, jw.WriteResult(Result{Host: "10.0.0.1", Port: 80, Status: scanner.Timeout}))
+	run.End = start.Add(2 * time.Second)
+	run.Summary = &stats.Summary{Hosts: 1, HostsWithOpen: 1, Open: 1, Timeout: 1, TopPorts: []stats.PortCount{{Port: 22, Count: 1}}, LatencyP50: 1500 * time.Microsecond}
+	require.NoError(t, jw.End(run))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(writer.Bytes(), &doc))
+
+	scan := doc["scan"].(map[string]any)
+	assert.Equal(t, "22,80", scan["ports"])
+	assert.Equal(t, float64(1000), scan["timeout_ms"])
+	assert.Equal(t, float64(2000), scan["elapsed_ms"])
+
+	summary := scan["summary"].(map[string]any)
+	assert.Equal(t, float64(1), summary["hosts_with_open"])
+	assert.Equal(t, float64(1), summary["timeout"])
+	assert.Equal(t, []any{map[string]any{"port": float64(22), "count": float64(1)}}, summary["top_ports"])
+	assert.Equal(t, 1.5, summary["latency_p50_ms"])
+
+	results := doc["results"].([]any)
+	require.Len(t, results, 2)
+
+	first := results[0].(map[string]any)
+	assert.Equal(t, "10.0.0.1", first["host"])
+	assert.Equal(t, float64(22), first["port"])
+	assert.Equal(t, "tcp", first["protocol"])
+	assert.Equal(t, "open", first["status"])
+	assert.Equal(t, 1.5, first["latency_ms"])
+	assert.Equal(t, "SSH-2.0", first["banner"])
+
+	second := results[1].(map[string]any)
+	assert.Equal(t, "timeout", second["status"])
+	assert.NotContains(t, second, "banner")
+}
+
+func TestJsonWriter_NoResults(t *testing.T) {
+	writer := &bytes.Buffer{}
+	jw := NewJsonWriter(writer)
+	run := &ScanRun{}
+
+	require.NoError(t, jw.Begin(run))
+	require.NoError(t, jw.End(run))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(writer.Bytes(), &doc))
+	assert.Equal(t, []any{}, doc["results"])
+}
+
+func TestNdjsonWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	nw := NewNdjsonWriter(writer)
+	run := &ScanRun{}
+
+	require.NoError(t, nw.Begin(run))
+	require.NoError(t, nw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: 2 * time.Millisecond}))
+	require.NoError(t, nw.WriteResult(Result{Host: "10.0.0.2", Port: 443, Status: scanner.Closed}))
+	require.NoError(t, nw.End(run))
+
+	expected := `{"host":"10.0.0.1","port":22,"protocol":"tcp","status":"open","latency_ms":2}` + "\n" +
+		`{"host":"10.0.0.2","port":443,"protocol":"tcp","status":"closed","latency_ms":0}` + "\n"
+	assert.Equal(t, expected, writer.String())
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// LiveHostWriter is an OutputWriter that writes a single result for each
+// host that is up, in host order, once the scan has finished. A host is up
+// if any of its ports answered, whether open or refusing connections; a
+// host that could not be reached is not. The result written
+// is the host's first open port, or its first closed port if none are open.
+type LiveHostWriter struct {
+	writer OutputWriter
+	best   map[string]Result
+}
+
+// NewLiveHostWriter creates a LiveHostWriter that writes to writer.
+func NewLiveHostWriter(writer OutputWriter) *LiveHostWriter {
+	return &LiveHostWriter{writer: writer, best: make(map[string]Result)}
+}
+
+// Begin begins the underlying writer.
+func (l *LiveHostWriter) Begin(run *ScanRun) error {
+	l.best = make(map[string]Result)
+	return l.writer.Begin(run)
+}
+
+// WriteResult keeps the result if it is the best answer from its host so
+// far.
+func (l *LiveHostWriter) WriteResult(r Result) error {
+	if !r.Answered() {
+		return nil
+	}
+	if best, ok := l.best[r.Host]; ok && !better(r, best) {
+		return nil
+	}
+	l.best[r.Host] = r
+	return nil
+}
+
+// End writes the answer from each host that is up and ends the underlying
+// writer.
+func (l *LiveHostWriter) End(run *ScanRun) error {
+	for _, host := range l.Hosts() {
+		if err := l.writer.WriteResult(l.best[host]); err != nil {
+			return err
+		}
+	}
+	return l.writer.End(run)
+}
+
+// Hosts returns the hosts that are up so far, in numeric address order.
+func (l *LiveHostWriter) Hosts() []string {
+	return slices.SortedFunc(maps.Keys(l.best), CompareHosts)
+}
+
+// better reports whether r is a better answer from a host than best: open
+// ports beat closed ones, then lower ports beat higher ones.
+func better(r, best Result) bool {
+	if (r.Status == scanner.Open) != (best.Status == scanner.Open) {
+		return r.Status == scanner.Open
+	}
+	return r.Port < best.Port
+}
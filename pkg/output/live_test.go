@@ -0,0 +1,38 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestLiveHostWriter(t *testing.T) {
+	collected := &collectingWriter{}
+	live := NewLiveHostWriter(collected)
+
+	run := &ScanRun{}
+	require.NoError(t, live.Begin(run))
+	for _, r := range []Result{
+		{Host: "10.0.0.10", Port: 443, Status: scanner.Closed},
+		{Host: "10.0.0.10", Port: 22, Status: scanner.Closed},
+		{Host: "10.0.0.9", Port: 22, Status: scanner.Closed},
+		{Host: "10.0.0.9", Port: 443, Status: scanner.Open},
+		{Host: "10.0.0.9", Port: 80, Status: scanner.Open},
+		{Host: "10.0.0.2", Port: 22, Status: scanner.Timeout},
+		{Host: "10.0.0.3", Port: 22, Status: scanner.Closed, Unreachable: true},
+	} {
+		require.NoError(t, live.WriteResult(r))
+	}
+	assert.Empty(t, collected.results)
+	assert.Equal(t, []string{"10.0.0.9", "10.0.0.10"}, live.Hosts())
+
+	require.NoError(t, live.End(run))
+	assert.Equal(t, []Result{
+		{Host: "10.0.0.9", Port: 80, Status: scanner.Open},
+		{Host: "10.0.0.10", Port: 22, Status: scanner.Closed},
+	}, collected.results)
+	assert.True(t, collected.ended)
+}
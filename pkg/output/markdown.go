@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// MarkdownWriter writes results as GitHub-flavored Markdown tables. The
+// whole document is buffered so that table columns line up.
+type MarkdownWriter struct {
+	writer      io.Writer
+	hosts       *hostGroups
+	groupByHost bool
+}
+
+// NewMarkdownWriter creates a new MarkdownWriter.
+func NewMarkdownWriter(writer io.Writer) *MarkdownWriter {
+	return &MarkdownWriter{
+		writer: writer,
+		hosts:  newHostGroups(),
+	}
+}
+
+// SetGroupByHost writes a heading and table per host instead of a single
+// table with a host column.
+func (m *MarkdownWriter) SetGroupByHost(groupByHost bool) {
+	m.groupByHost = groupByHost
+}
+
+// Begin starts a new document.
+func (m *MarkdownWriter) Begin(run *ScanRun) error {
+	m.hosts = newHostGroups()
+	return nil
+}
+
+// WriteResult buffers a single result under its host.
+func (m *MarkdownWriter) WriteResult(r Result) error {
+	m.hosts.add(r)
+	return nil
+}
+
+// End writes the complete document.
+func (m *MarkdownWriter) End(run *ScanRun) error {
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "## Scan of %s (ports %s)\n\n", strings.Join(run.Targets, ", "), run.Ports)
+
+	counts := make(map[scanner.Status]int)
+	hosts := m.hosts.hosts()
+	var rows [][]string
+	for _, host := range hosts {
+		results := m.hosts.take(host)
+		if m.groupByHost {
+			rows = nil
+		}
+		for _, r := range results {
+			counts[r.Status]++
+			row := []string{strconv.Itoa(r.Port), r.Status.String(), r.Service, r.Banner}
+			if !m.groupByHost {
+				row = append([]string{r.Host}, row...)
+			}
+			rows = append(rows, row)
+		}
+		if m.groupByHost {
+			fmt.Fprintf(&doc, "### %s\n\n", host)
+			writeMarkdownTable(&doc, []string{"Port", "Status", "Service", "Banner"}, []bool{true, false, false, false}, rows)
+			doc.WriteString("\n")
+		}
+	}
+
+	if len(hosts) == 0 {
+		doc.WriteString("No results.\n\n")
+	} else if !m.groupByHost {
+		writeMarkdownTable(&doc, []string{"Host", "Port", "Status", "Service", "Banner"}, []bool{false, true, false, false, false}, rows)
+		doc.WriteString("\n")
+	}
+
+	doc.WriteString("### Summary\n\n")
+	counts = statusCounts(run, counts)
+	var summary [][]string
+	for _, status := range []scanner.Status{scanner.Open, scanner.Closed, scanner.Timeout} {
+		summary = append(summary, []string{status.String(), strconv.Itoa(counts[status])})
+	}
+	summary = append(summary, []string{"Hosts with results", strconv.Itoa(len(hosts))})
+	writeMarkdownTable(&doc, []string{"Status", "Count"}, []bool{false, true}, summary)
+	fmt.Fprintf(&doc, "\nScanned %d hosts in %s.\n", run.HostCount, run.Elapsed().Round(time.Millisecond))
+	if s := run.Summary; s != nil {
+		fmt.Fprintf(&doc, "%d of %d hosts had open ports. Connect latency p50 %s, p95 %s.\n",
+			s.HostsWithOpen, s.Hosts, s.LatencyP50.Round(time.Microsecond), s.LatencyP95.Round(time.Microsecond))
+		if len(s.TopPorts) > 0 {
+			top := make([]string, len(s.TopPorts))
+			for i, pc := range s.TopPorts {
+				top[i] = fmt.Sprintf("%d (%d)", pc.Port, pc.Count)
+			}
+			fmt.Fprintf(&doc, "Most common open ports: %s.\n", strings.Join(top, ", "))
+		}
+	}
+
+	_, err := io.WriteString(m.writer, doc.String())
+	return err
+}
+
+// writeMarkdownTable writes a table with every column padded to its widest
+// cell. Columns marked in rightAlign are right aligned.
+func writeMarkdownTable(w *strings.Builder, headers []string, rightAlign []bool, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = max(utf8.RuneCountInString(h), 3)
+	}
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = escapeMarkdownCell(cell)
+			widths[j] = max(widths[j], utf8.RuneCountInString(escaped[i][j]))
+		}
+	}
+
+	writeRow := func(cells []string) {
+		w.WriteString("|")
+		for i, cell := range cells {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if rightAlign[i] {
+				fmt.Fprintf(w, " %s%s |", pad, cell)
+			} else {
+				fmt.Fprintf(w, " %s%s |", cell, pad)
+			}
+		}
+		w.WriteString("\n")
+	}
+
+	writeRow(headers)
+	w.WriteString("|")
+	for i, width := range widths {
+		if rightAlign[i] {
+			fmt.Fprintf(w, " %s: |", strings.Repeat("-", width-1))
+		} else {
+			fmt.Fprintf(w, " %s |", strings.Repeat("-", width))
+		}
+	}
+	w.WriteString("\n")
+	for _, row := range escaped {
+		writeRow(row)
+	}
+}
+
+// escapeMarkdownCell makes a value safe to place in a table cell.
+func escapeMarkdownCell(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "|", "\\|", "\r", " ", "\n", " ").Replace(s)
+}
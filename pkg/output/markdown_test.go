@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+func writeMarkdown(t *testing.T, mw *MarkdownWriter) {
+	t.Helper()
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{Targets: []string{"10.0.0.0/29"}, Ports: "22,80", HostCount: 6, Start: start}
+
+	require.NoError(t, mw.Begin(run))
+	require.NoError(t, mw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.10", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, mw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open, Banner: "a|b"})))
+	run.End = start.Add(1500 * time.Millisecond)
+	require.NoError(t, mw.End(run))
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	writeMarkdown(t, NewMarkdownWriter(writer))
+
+	expected := "## Scan of 10.0.0.0/29 (ports 22,80)\n" +
+		"\n" +
+		"| Host      | Port | Status | Service | Banner |\n" +
+		"| --------- | ---: | ------ | ------- | ------ |\n" +
+		"| 10.0.0.5  |   22 | Open   | ssh     | a\\|b   |\n" +
+		"| 10.0.0.10 |   80 | Closed | http    |        |\n" +
+		"\n" +
+		"### Summary\n" +
+		"\n" +
+		"| Status             | Count |\n" +
+		"| ------------------ | ----: |\n" +
+		"| Open               |     1 |\n" +
+		"| Closed             |     1 |\n" +
+		"| Timed Out          |     0 |\n" +
+		"| Hosts with results |     2 |\n" +
+		"\n" +
+		"Scanned 6 hosts in 1.5s.\n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestMarkdownWriter_GroupByHost(t *testing.T) {
+	writer := &bytes.Buffer{}
+	mw := NewMarkdownWriter(writer)
+	mw.SetGroupByHost(true)
+	writeMarkdown(t, mw)
+
+	assert.Contains(t, writer.String(), "### 10.0.0.5\n"+
+		"\n"+
+		"| Port | Status | Service | Banner |\n"+
+		"| ---: | ------ | ------- | ------ |\n"+
+		"|   22 | Open   | ssh     | a\\|b   |\n"+
+		"\n"+
+		"### 10.0.0.10\n")
+}
+
+func TestEscapeMarkdownCell(t *testing.T) {
+	assert.Equal(t, `a\|b\\c d`, escapeMarkdownCell("a|b\\c\nd"))
+}
+
+func TestMarkdownWriter_Summary(t *testing.T) {
+	writer := &bytes.Buffer{}
+	mw := NewMarkdownWriter(writer)
+	run := &ScanRun{
+		Summary: &stats.Summary{
+			Hosts:         6,
+			HostsWithOpen: 1,
+			Open:          1,
+			Closed:        11,
+			TopPorts:      []stats.PortCount{{Port: 22, Count: 1}},
+			LatencyP50:    time.Millisecond,
+			LatencyP95:    5 * time.Millisecond,
+		},
+	}
+
+	require.NoError(t, mw.Begin(run))
+	require.NoError(t, mw.End(run))
+
+	assert.Contains(t, writer.String(), "| Closed             |    11 |\n", "filtered results are counted")
+	assert.Contains(t, writer.String(), "1 of 6 hosts had open ports. Connect latency p50 1ms, p95 5ms.\nMost common open ports: 22 (1).\n")
+}
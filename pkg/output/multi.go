@@ -0,0 +1,110 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// Filter reports whether a result should be written.
+type Filter func(Result) bool
+
+// StatusFilter returns a Filter that passes results with any of the given
+// statuses.
+func StatusFilter(statuses ...scanner.Status) Filter {
+	return func(r Result) bool {
+		return slices.Contains(statuses, r.Status)
+	}
+}
+
+// ParseStatusFilter parses a comma-separated list of statuses such as
+// "open,timeout". "all" passes every result.
+func ParseStatusFilter(spec string) (Filter, error) {
+	if spec == "all" {
+		return nil, nil
+	}
+
+	var statuses []scanner.Status
+	for _, name := range strings.Split(spec, ",") {
+		var status scanner.Status
+		if err := status.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return nil, fmt.Errorf("invalid status filter: %s", name)
+		}
+		statuses = append(statuses, status)
+	}
+	return StatusFilter(statuses...), nil
+}
+
+// multiTarget is a single writer of a MultiWriter and its filter.
+type multiTarget struct {
+	writer OutputWriter
+	filter Filter
+}
+
+// MultiWriter is an OutputWriter that writes every result to several other
+// writers, each with its own filter.
+type MultiWriter struct {
+	targets []multiTarget
+}
+
+// NewMultiWriter creates a new, empty MultiWriter.
+func NewMultiWriter() *MultiWriter {
+	return &MultiWriter{}
+}
+
+// Add adds a writer. Only results that pass filter are written to it; a nil
+// filter passes every result.
+func (m *MultiWriter) Add(writer OutputWriter, filter Filter) {
+	m.targets = append(m.targets, multiTarget{writer: writer, filter: filter})
+}
+
+// Begin begins every writer.
+func (m *MultiWriter) Begin(run *ScanRun) error {
+	return m.each(func(t multiTarget) error {
+		return t.writer.Begin(run)
+	})
+}
+
+// WriteResult writes a result to every writer whose filter it passes, and
+// skips it on the others.
+func (m *MultiWriter) WriteResult(r Result) error {
+	return m.each(func(t multiTarget) error {
+		if t.filter != nil && !t.filter(r) {
+			if skipWriter, ok := t.writer.(SkipWriter); ok {
+				return skipWriter.SkipResult(r)
+			}
+			return nil
+		}
+		return t.writer.WriteResult(r)
+	})
+}
+
+// EndHost ends a host on every writer that groups results by host.
+func (m *MultiWriter) EndHost(host string) error {
+	return m.each(func(t multiTarget) error {
+		if hostWriter, ok := t.writer.(HostWriter); ok {
+			return hostWriter.EndHost(host)
+		}
+		return nil
+	})
+}
+
+// End ends every writer.
+func (m *MultiWriter) End(run *ScanRun) error {
+	return m.each(func(t multiTarget) error {
+		return t.writer.End(run)
+	})
+}
+
+// each calls fn for every writer. A failing writer does not stop the others
+// from being written to; all errors are returned together.
+func (m *MultiWriter) each(fn func(multiTarget) error) error {
+	var errs []error
+	for _, t := range m.targets {
+		errs = append(errs, fn(t))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestParseStatusFilter(t *testing.T) {
+	filter, err := ParseStatusFilter("open, timeout")
+	require.NoError(t, err)
+	assert.True(t, filter(Result{Status: scanner.Open}))
+	assert.True(t, filter(Result{Status: scanner.Timeout}))
+	assert.False(t, filter(Result{Status: scanner.Closed}))
+
+	filter, err = ParseStatusFilter("all")
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = ParseStatusFilter("open,shut")
+	assert.EqualError(t, err, "invalid status filter: shut")
+}
+
+func TestMultiWriter(t *testing.T) {
+	all := &recordingWriter{}
+	open := &collectingWriter{}
+
+	multi := NewMultiWriter()
+	multi.Add(all, nil)
+	multi.Add(open, StatusFilter(scanner.Open))
+
+	run := &ScanRun{}
+	require.NoError(t, multi.Begin(run))
+	require.NoError(t, multi.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open}))
+	require.NoError(t, multi.WriteResult(Result{Host: "10.0.0.1", Port: 80, Status: scanner.Closed}))
+	require.NoError(t, multi.EndHost("10.0.0.1"))
+	require.NoError(t, multi.End(run))
+
+	assert.Equal(t, []string{"begin", "result 10.0.0.1", "result 10.0.0.1", "end 10.0.0.1", "end"}, all.calls)
+	assert.Equal(t, []Result{{Host: "10.0.0.1", Port: 22, Status: scanner.Open}}, open.results)
+	assert.True(t, open.ended)
+}
+
+func TestMultiWriter_ErrorsDoNotStopOtherWriters(t *testing.T) {
+	good := &bytes.Buffer{}
+
+	multi := NewMultiWriter()
+	multi.Add(NewCsvWriter(failingWriter{}), nil)
+	multi.Add(NewCsvWriter(good), nil)
+
+	err := multi.Begin(&ScanRun{})
+	assert.ErrorContains(t, err, "disk full")
+	assert.Equal(t, "IP Address,Port,Status,Latency (ms),Banner\n", good.String())
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// NdjsonWriter writes each result as a single line of JSON as soon as it
+// arrives.
+type NdjsonWriter struct {
+	encoder *json.Encoder
+}
+
+// NewNdjsonWriter creates a new NdjsonWriter.
+func NewNdjsonWriter(writer io.Writer) *NdjsonWriter {
+	return &NdjsonWriter{
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// Begin is a no-op; NDJSON streams carry no header.
+func (n *NdjsonWriter) Begin(run *ScanRun) error {
+	return nil
+}
+
+// WriteResult writes a single result line.
+func (n *NdjsonWriter) WriteResult(r Result) error {
+	return n.encoder.Encode(newJsonResult(r))
+}
+
+// End is a no-op; NDJSON streams carry no footer.
+func (n *NdjsonWriter) End(run *ScanRun) error {
+	return nil
+}
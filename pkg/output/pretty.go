@@ -0,0 +1,152 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// ANSI escape sequences used by PrettyWriter.
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiDim       = "\033[2m"
+	ansiGreen     = "\033[32m"
+	ansiYellow    = "\033[33m"
+	ansiClearLine = "\r\033[K"
+)
+
+// PrettyWriter writes human friendly output with the results of each host
+// grouped under a heading. A host is written once all of its ports have
+// been scanned.
+type PrettyWriter struct {
+	writer  io.Writer
+	hosts   *hostGroups
+	color   bool
+	live    bool
+	pending int
+	status  bool
+}
+
+// NewPrettyWriter creates a new PrettyWriter.
+func NewPrettyWriter(writer io.Writer) *PrettyWriter {
+	return &PrettyWriter{
+		writer: writer,
+		hosts:  newHostGroups(),
+	}
+}
+
+// SetColor colors ports by status: open in green, timed out in yellow and
+// closed dimmed.
+func (p *PrettyWriter) SetColor(color bool) {
+	p.color = color
+}
+
+// SetLive shows a status line below the completed hosts that is redrawn as
+// results arrive. It should only be enabled when writing to a terminal.
+func (p *PrettyWriter) SetLive(live bool) {
+	p.live = live
+}
+
+// Begin starts a new scan.
+func (p *PrettyWriter) Begin(run *ScanRun) error {
+	p.hosts = newHostGroups()
+	p.pending = 0
+	return nil
+}
+
+// WriteResult buffers a single result until its host is complete.
+func (p *PrettyWriter) WriteResult(r Result) error {
+	p.hosts.add(r)
+	p.pending++
+	return p.drawStatus()
+}
+
+// EndHost writes the heading and results for a completed host.
+func (p *PrettyWriter) EndHost(host string) error {
+	results := p.hosts.take(host)
+	if len(results) == 0 {
+		return nil
+	}
+	p.pending -= len(results)
+
+	var block strings.Builder
+	open := 0
+	for _, r := range results {
+		if r.Status == scanner.Open {
+			open++
+		}
+	}
+	block.WriteString(p.paint(ansiBold, host))
+	fmt.Fprintf(&block, " %s\n", p.paint(ansiDim, fmt.Sprintf("(%d open)", open)))
+
+	for _, r := range results {
+		line := fmt.Sprintf("  %-11s %-10s %-15s %s", fmt.Sprintf("%d/tcp", r.Port), r.Status, r.Service, r.Banner)
+		block.WriteString(p.paint(statusColor(r.Status), strings.TrimRight(line, " ")))
+		block.WriteString("\n")
+	}
+	block.WriteString("\n")
+
+	if err := p.clearStatus(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(p.writer, block.String()); err != nil {
+		return err
+	}
+	return p.drawStatus()
+}
+
+// End writes any hosts that are still buffered and removes the status line.
+func (p *PrettyWriter) End(run *ScanRun) error {
+	for _, host := range p.hosts.hosts() {
+		if err := p.EndHost(host); err != nil {
+			return err
+		}
+	}
+	return p.clearStatus()
+}
+
+// drawStatus redraws the live status line.
+func (p *PrettyWriter) drawStatus() error {
+	if !p.live {
+		return nil
+	}
+	text := fmt.Sprintf("scanning... %d results pending from %d hosts", p.pending, len(p.hosts.results))
+	_, err := io.WriteString(p.writer, ansiClearLine+p.paint(ansiDim, text))
+	p.status = true
+	return err
+}
+
+// clearStatus erases the live status line, if one is drawn.
+func (p *PrettyWriter) clearStatus() error {
+	if !p.status {
+		return nil
+	}
+	p.status = false
+	_, err := io.WriteString(p.writer, ansiClearLine)
+	return err
+}
+
+// paint wraps text in an ANSI style when color is enabled.
+func (p *PrettyWriter) paint(style, text string) string {
+	if !p.color || style == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// statusColor returns the ANSI style for a status.
+func statusColor(s scanner.Status) string {
+	switch s {
+	case scanner.Open:
+		return ansiGreen
+	case scanner.Timeout:
+		return ansiYellow
+	case scanner.Closed:
+		return ansiDim
+	default:
+		return ""
+	}
+}
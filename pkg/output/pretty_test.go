@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestPrettyWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	pw := NewPrettyWriter(writer)
+	run := &ScanRun{}
+
+	require.NoError(t, pw.Begin(run))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.6", Port: 22, Status: scanner.Timeout})))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open, Banner: "SSH-2.0"})))
+	require.NoError(t, pw.EndHost("10.0.0.5"))
+	require.NoError(t, pw.End(run))
+
+	expected := "10.0.0.5 (1 open)\n" +
+		"  22/tcp      Open       ssh             SSH-2.0\n" +
+		"  80/tcp      Closed     http\n" +
+		"\n" +
+		"10.0.0.6 (0 open)\n" +
+		"  22/tcp      Timed Out  ssh\n" +
+		"\n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestPrettyWriter_Color(t *testing.T) {
+	writer := &bytes.Buffer{}
+	pw := NewPrettyWriter(writer)
+	pw.SetColor(true)
+	run := &ScanRun{}
+
+	require.NoError(t, pw.Begin(run))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open})))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 443, Status: scanner.Timeout})))
+	require.NoError(t, pw.End(run))
+
+	out := writer.String()
+	assert.Contains(t, out, ansiBold+"10.0.0.5"+ansiReset)
+	assert.Contains(t, out, ansiGreen+"  22/tcp      Open       ssh"+ansiReset)
+	assert.Contains(t, out, ansiDim+"  80/tcp      Closed     http"+ansiReset)
+	assert.Contains(t, out, ansiYellow+"  443/tcp     Timed Out  https"+ansiReset)
+}
+
+func TestPrettyWriter_Live(t *testing.T) {
+	writer := &bytes.Buffer{}
+	pw := NewPrettyWriter(writer)
+	pw.SetLive(true)
+	run := &ScanRun{}
+
+	require.NoError(t, pw.Begin(run))
+	require.NoError(t, pw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open})))
+	assert.Equal(t, ansiClearLine+"scanning... 1 results pending from 1 hosts", writer.String())
+
+	writer.Reset()
+	require.NoError(t, pw.EndHost("10.0.0.5"))
+	assert.Equal(t, ansiClearLine+"10.0.0.5 (1 open)\n  22/tcp      Open       ssh\n\n"+ansiClearLine+"scanning... 0 results pending from 0 hosts", writer.String())
+
+	writer.Reset()
+	require.NoError(t, pw.End(run))
+	assert.Equal(t, ansiClearLine, writer.String())
+}
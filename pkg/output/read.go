@@ -0,0 +1,191 @@
+package output
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/services"
+)
+
+// ReadFile reads the results from a file written by the json, ndjson, csv or
+// xml output formats. The format is detected from the file's contents.
+func ReadFile(path string) ([]Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	results, err := ReadResults(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// ReadResults reads results written by the json, ndjson, csv or xml output
+// formats, detecting which from the first character of the input.
+func ReadResults(r io.Reader) ([]Result, error) {
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+			continue
+		case '{':
+			return readJson(reader)
+		case '<':
+			return readXml(reader)
+		default:
+			return readCsv(reader)
+		}
+	}
+}
+
+// readJson reads a JSON document or an NDJSON stream of results.
+func readJson(r io.Reader) ([]Result, error) {
+	decoder := json.NewDecoder(r)
+
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Results *[]jsonResult `json:"results"`
+	}
+	if err := json.Unmarshal(first, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Results != nil {
+		return fromJsonResults(*doc.Results), nil
+	}
+
+	var jrs []jsonResult
+	for raw := first; ; {
+		var jr jsonResult
+		if err := json.Unmarshal(raw, &jr); err != nil {
+			return nil, err
+		}
+		jrs = append(jrs, jr)
+
+		raw = nil
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return fromJsonResults(jrs), nil
+}
+
+func fromJsonResults(jrs []jsonResult) []Result {
+	results := make([]Result, len(jrs))
+	for i, jr := range jrs {
+		results[i] = Result{
+			Host:    jr.Host,
+			Port:    jr.Port,
+			Status:  jr.Status,
+			Latency: time.Duration(jr.LatencyMs * float64(time.Millisecond)),
+			Banner:  jr.Banner,
+			Service: jr.Service,
+		}
+	}
+	return results
+}
+
+// readXml reads an nmap XML document.
+func readXml(r io.Reader) ([]Result, error) {
+	var doc nmapRun
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, h := range doc.Hosts {
+		for _, p := range h.Ports {
+			result := Result{Host: h.Address.Addr, Port: p.PortID}
+			switch p.State.State {
+			case "open":
+				result.Status = scanner.Open
+			case "closed":
+				result.Status = scanner.Closed
+			default:
+				result.Status = scanner.Timeout
+			}
+			if p.Service != nil {
+				result.Service = p.Service.Name
+			}
+			for _, script := range p.Scripts {
+				if script.ID == "banner" {
+					result.Banner = script.Output
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// readCsv reads CSV written by CsvWriter.
+func readCsv(r io.Reader) ([]Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 3 || header[0] != csvHeaders[0] {
+		return nil, errors.New("unrecognised CSV header")
+	}
+
+	var results []Result
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("short CSV record: %q", strings.Join(record, ","))
+		}
+
+		port, err := strconv.Atoi(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %s", record[1])
+		}
+		result := Result{Host: record[0], Port: port, Service: services.Lookup(port)}
+		if err := result.Status.UnmarshalText([]byte(record[2])); err != nil {
+			return nil, err
+		}
+		if len(record) > 3 {
+			if ms, err := strconv.ParseFloat(record[3], 64); err == nil {
+				result.Latency = time.Duration(ms * float64(time.Millisecond))
+			}
+		}
+		if len(record) > 4 {
+			result.Banner = record[4]
+		}
+		results = append(results, result)
+	}
+}
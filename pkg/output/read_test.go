@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+var readTestResults = []Result{
+	NewResult(scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: 2 * time.Millisecond, Banner: "SSH-2.0, \"quoted\""}),
+	NewResult(scanner.Port{Host: "10.0.0.1", Port: 80, Status: scanner.Closed, Latency: time.Millisecond}),
+	NewResult(scanner.Port{Host: "2001:db8::1", Port: 443, Status: scanner.Timeout}),
+}
+
+func writeAll(t *testing.T, w OutputWriter, results []Result) {
+	t.Helper()
+
+	run := &ScanRun{Start: time.Now()}
+	require.NoError(t, w.Begin(run))
+	for _, r := range results {
+		require.NoError(t, w.WriteResult(r))
+	}
+	run.End = time.Now()
+	require.NoError(t, w.End(run))
+}
+
+func TestReadResults_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		newWriter func(*bytes.Buffer) OutputWriter
+	}{
+		{name: "json", newWriter: func(b *bytes.Buffer) OutputWriter { return NewJsonWriter(b) }},
+		{name: "ndjson", newWriter: func(b *bytes.Buffer) OutputWriter { return NewNdjsonWriter(b) }},
+		{name: "csv", newWriter: func(b *bytes.Buffer) OutputWriter { return NewCsvWriter(b) }},
+		{name: "xml", newWriter: func(b *bytes.Buffer) OutputWriter { return NewXmlWriter(b) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writeAll(t, tt.newWriter(buf), readTestResults)
+
+			results, err := ReadResults(buf)
+			require.NoError(t, err)
+			require.Len(t, results, len(readTestResults))
+
+			for i, want := range readTestResults {
+				got := results[i]
+				assert.Equal(t, want.Host, got.Host)
+				assert.Equal(t, want.Port, got.Port)
+				assert.Equal(t, want.Status, got.Status)
+				assert.Equal(t, want.Service, got.Service)
+				assert.Equal(t, want.Banner, got.Banner)
+			}
+		})
+	}
+}
+
+func TestReadResults_Empty(t *testing.T) {
+	results, err := ReadResults(strings.NewReader("  \n"))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestReadResults_Invalid(t *testing.T) {
+	_, err := ReadResults(strings.NewReader("not,a,scan\n"))
+	assert.EqualError(t, err, "unrecognised CSV header")
+
+	_, err = ReadResults(strings.NewReader("IP Address,Port,Status\n10.0.0.1,ssh,Open\n"))
+	assert.EqualError(t, err, "invalid port: ssh")
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	buf := &bytes.Buffer{}
+	writeAll(t, NewJsonWriter(buf), readTestResults)
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	results, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	_, err = ReadFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/services"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+// Result is a single scan result with typed fields.
+type Result struct {
+	Host    string
+	Port    int
+	Status  scanner.Status
+	Latency time.Duration
+	Banner  string
+	// Service is the well-known service name for the port, if any.
+	Service string
+	// Unreachable is set on a closed port whose host could not be reached.
+	Unreachable bool
+}
+
+// NewResult creates a Result from a scanned port.
+func NewResult(p scanner.Port) Result {
+	return Result{
+		Host:        p.Host,
+		Port:        p.Port,
+		Status:      p.Status,
+		Latency:     p.Latency,
+		Banner:      p.Banner,
+		Service:     services.Lookup(p.Port),
+		Unreachable: p.Unreachable,
+	}
+}
+
+// Answered reports whether the port's host answered, by accepting or
+// refusing the connection, which shows that the host is up.
+func (r Result) Answered() bool {
+	return r.Status == scanner.Open || r.Status == scanner.Closed && !r.Unreachable
+}
+
+// ScanRun describes a single invocation of the scanner.
+type ScanRun struct {
+	// Command is the command line that started the scan.
+	Command string
+	Targets []string
+	Ports   string
+	// PortCount is the number of ports scanned on each host.
+	PortCount int
+	Timeout   time.Duration
+	// HostCount is the number of hosts being scanned.
+	HostCount int
+	Start     time.Time
+	End       time.Time
+	// Summary holds statistics over every result of the scan, including
+	// those that were filtered out. It is set before End is called.
+	Summary *stats.Summary
+}
+
+// Elapsed returns how long the scan took. It is zero until the scan ends.
+func (run *ScanRun) Elapsed() time.Duration {
+	if run.End.IsZero() {
+		return 0
+	}
+	return run.End.Sub(run.Start)
+}
+
+// statusCounts returns the number of results of each status. They are taken
+// from the run's Summary when it has one, so that results filtered out of
+// an output are still counted, and otherwise from counted.
+func statusCounts(run *ScanRun, counted map[scanner.Status]int) map[scanner.Status]int {
+	s := run.Summary
+	if s == nil {
+		return counted
+	}
+	return map[scanner.Status]int{
+		scanner.Open:    s.Open,
+		scanner.Closed:  s.Closed,
+		scanner.Timeout: s.Timeout,
+	}
+}
@@ -0,0 +1,273 @@
+package output
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultSortBufferSize is the number of results a Sorter holds in memory
+// before spilling them to a temporary file.
+const DefaultSortBufferSize = 100000
+
+// CompareFunc orders two results, returning a negative number when a sorts
+// before b, a positive number when it sorts after and zero when equal.
+type CompareFunc func(a, b Result) int
+
+// sortKeys are the comparisons available to ParseSortKeys.
+var sortKeys = map[string]CompareFunc{
+	"host":    func(a, b Result) int { return CompareHosts(a.Host, b.Host) },
+	"port":    func(a, b Result) int { return cmp.Compare(a.Port, b.Port) },
+	"status":  func(a, b Result) int { return cmp.Compare(a.Status, b.Status) },
+	"latency": func(a, b Result) int { return cmp.Compare(a.Latency, b.Latency) },
+}
+
+// ParseSortKeys parses a comma-separated list of sort keys such as
+// "host,port". A key prefixed with "-" sorts in descending order. Results
+// that are equal on every key are ordered by host and then port so that the
+// output is stable between runs.
+func ParseSortKeys(spec string) (CompareFunc, error) {
+	var compares []CompareFunc
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.TrimSpace(key)
+		descending := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+
+		compare, ok := sortKeys[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort key: %s", key)
+		}
+		if descending {
+			ascending := compare
+			compare = func(a, b Result) int { return ascending(b, a) }
+		}
+		compares = append(compares, compare)
+	}
+	compares = append(compares, sortKeys["host"], sortKeys["port"])
+
+	return func(a, b Result) int {
+		for _, compare := range compares {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}, nil
+}
+
+// Sorter is an OutputWriter that buffers results and passes them on to
+// another writer in sorted order once the scan has finished. When more
+// results arrive than fit in its buffer, sorted runs are spilled to
+// temporary files and merged at the end, so memory use stays bounded.
+type Sorter struct {
+	writer     OutputWriter
+	compare    CompareFunc
+	bufferSize int
+	buffer     []Result
+	spills     []*os.File
+}
+
+// NewSorter creates a new Sorter that writes to writer.
+func NewSorter(writer OutputWriter, compare CompareFunc) *Sorter {
+	return &Sorter{
+		writer:     writer,
+		compare:    compare,
+		bufferSize: DefaultSortBufferSize,
+	}
+}
+
+// SetBufferSize sets how many results are held in memory before spilling.
+func (s *Sorter) SetBufferSize(size int) {
+	s.bufferSize = size
+}
+
+// Begin passes the start of the scan straight through.
+func (s *Sorter) Begin(run *ScanRun) error {
+	s.buffer = nil
+	return s.writer.Begin(run)
+}
+
+// WriteResult buffers a single result, spilling the buffer to disk if it is
+// full.
+func (s *Sorter) WriteResult(r Result) error {
+	s.buffer = append(s.buffer, r)
+	if s.bufferSize > 0 && len(s.buffer) >= s.bufferSize {
+		return s.spill()
+	}
+	return nil
+}
+
+// End writes every result in order and then ends the underlying writer.
+func (s *Sorter) End(run *ScanRun) (err error) {
+	defer func() {
+		err = errors.Join(err, s.removeSpills())
+	}()
+
+	slices.SortStableFunc(s.buffer, s.compare)
+
+	if len(s.spills) == 0 {
+		for _, r := range s.buffer {
+			if err := s.writer.WriteResult(r); err != nil {
+				return err
+			}
+		}
+	} else if err := s.merge(); err != nil {
+		return err
+	}
+	s.buffer = nil
+
+	return s.writer.End(run)
+}
+
+// Close deletes the temporary files of a scan that did not end, for example
+// because writing a result failed. It does nothing once End has been called.
+func (s *Sorter) Close() error {
+	s.buffer = nil
+	return s.removeSpills()
+}
+
+// spill sorts the buffer and writes it to a temporary file.
+func (s *Sorter) spill() error {
+	slices.SortStableFunc(s.buffer, s.compare)
+
+	f, err := os.CreateTemp("", "network-scanner-sort-*")
+	if err != nil {
+		return fmt.Errorf("creating sort file: %w", err)
+	}
+	s.spills = append(s.spills, f)
+
+	w := bufio.NewWriter(f)
+	encoder := gob.NewEncoder(w)
+	for _, r := range s.buffer {
+		if err := encoder.Encode(r); err != nil {
+			return fmt.Errorf("writing sort file: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing sort file: %w", err)
+	}
+
+	s.buffer = s.buffer[:0]
+	return nil
+}
+
+// merge performs a k-way merge of the spilled runs and the in-memory buffer.
+func (s *Sorter) merge() error {
+	runs := &mergeHeap{compare: s.compare}
+
+	for _, f := range s.spills {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("reading sort file: %w", err)
+		}
+		decoder := gob.NewDecoder(bufio.NewReader(f))
+		next := func() (Result, bool, error) {
+			var r Result
+			if err := decoder.Decode(&r); err != nil {
+				if err == io.EOF {
+					return r, false, nil
+				}
+				return r, false, fmt.Errorf("reading sort file: %w", err)
+			}
+			return r, true, nil
+		}
+		if err := runs.push(next); err != nil {
+			return err
+		}
+	}
+
+	buffer := s.buffer
+	if err := runs.push(func() (Result, bool, error) {
+		if len(buffer) == 0 {
+			return Result{}, false, nil
+		}
+		r := buffer[0]
+		buffer = buffer[1:]
+		return r, true, nil
+	}); err != nil {
+		return err
+	}
+
+	for runs.Len() > 0 {
+		head := runs.runs[0]
+		if err := s.writer.WriteResult(head.current); err != nil {
+			return err
+		}
+
+		r, ok, err := head.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			head.current = r
+			heap.Fix(runs, 0)
+		} else {
+			heap.Pop(runs)
+		}
+	}
+	return nil
+}
+
+// removeSpills closes and deletes the temporary files.
+func (s *Sorter) removeSpills() error {
+	var errs []error
+	for _, f := range s.spills {
+		errs = append(errs, f.Close(), os.Remove(f.Name()))
+	}
+	s.spills = nil
+	return errors.Join(errs...)
+}
+
+// mergeRun is a sorted run of results being merged.
+type mergeRun struct {
+	current Result
+	next    func() (Result, bool, error)
+}
+
+// mergeHeap is a heap of runs ordered by their current result. Runs are
+// ordered by index when their results are equal so the merge is stable.
+type mergeHeap struct {
+	runs    []*mergeRun
+	index   map[*mergeRun]int
+	compare CompareFunc
+}
+
+// push adds a run to the heap if it has any results.
+func (h *mergeHeap) push(next func() (Result, bool, error)) error {
+	r, ok, err := next()
+	if err != nil || !ok {
+		return err
+	}
+	if h.index == nil {
+		h.index = make(map[*mergeRun]int)
+	}
+	run := &mergeRun{current: r, next: next}
+	h.index[run] = len(h.index)
+	heap.Push(h, run)
+	return nil
+}
+
+func (h *mergeHeap) Len() int { return len(h.runs) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	if c := h.compare(h.runs[i].current, h.runs[j].current); c != 0 {
+		return c < 0
+	}
+	return h.index[h.runs[i]] < h.index[h.runs[j]]
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *mergeHeap) Push(x any) { h.runs = append(h.runs, x.(*mergeRun)) }
+
+func (h *mergeHeap) Pop() any {
+	run := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return run
+}
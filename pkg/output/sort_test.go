@@ -0,0 +1,146 @@
+package output
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// collectingWriter keeps every result written to it.
+type collectingWriter struct {
+	results []Result
+	ended   bool
+}
+
+func (w *collectingWriter) Begin(run *ScanRun) error { return nil }
+
+func (w *collectingWriter) WriteResult(r Result) error {
+	w.results = append(w.results, r)
+	return nil
+}
+
+func (w *collectingWriter) End(run *ScanRun) error {
+	w.ended = true
+	return nil
+}
+
+func TestParseSortKeys(t *testing.T) {
+	results := []Result{
+		{Host: "10.0.0.10", Port: 80, Status: scanner.Closed, Latency: 3 * time.Millisecond},
+		{Host: "10.0.0.9", Port: 443, Status: scanner.Open, Latency: 1 * time.Millisecond},
+		{Host: "10.0.0.9", Port: 22, Status: scanner.Timeout, Latency: 2 * time.Millisecond},
+	}
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "host", want: []string{"10.0.0.9:22", "10.0.0.9:443", "10.0.0.10:80"}},
+		{spec: "port", want: []string{"10.0.0.9:22", "10.0.0.10:80", "10.0.0.9:443"}},
+		{spec: "status", want: []string{"10.0.0.9:443", "10.0.0.10:80", "10.0.0.9:22"}},
+		{spec: "-latency", want: []string{"10.0.0.10:80", "10.0.0.9:22", "10.0.0.9:443"}},
+		{spec: "host, -port", want: []string{"10.0.0.9:443", "10.0.0.9:22", "10.0.0.10:80"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			compare, err := ParseSortKeys(tt.spec)
+			require.NoError(t, err)
+
+			writer := &collectingWriter{}
+			sorter := NewSorter(writer, compare)
+			run := &ScanRun{}
+			require.NoError(t, sorter.Begin(run))
+			for _, r := range results {
+				require.NoError(t, sorter.WriteResult(r))
+			}
+			require.NoError(t, sorter.End(run))
+
+			var got []string
+			for _, r := range writer.results {
+				got = append(got, r.Host+":"+strconv.Itoa(r.Port))
+			}
+			assert.Equal(t, tt.want, got)
+			assert.True(t, writer.ended)
+		})
+	}
+}
+
+func TestParseSortKeys_Invalid(t *testing.T) {
+	_, err := ParseSortKeys("host,colour")
+	assert.EqualError(t, err, "invalid sort key: colour")
+}
+
+func TestSorter_Spills(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	compare, err := ParseSortKeys("host,port")
+	require.NoError(t, err)
+
+	var results []Result
+	for host := 1; host <= 20; host++ {
+		for port := 1; port <= 50; port++ {
+			results = append(results, Result{
+				Host:    "10.0.0." + strconv.Itoa(host),
+				Port:    port,
+				Status:  scanner.Status(port % 3),
+				Latency: time.Duration(port) * time.Millisecond,
+				Banner:  "banner",
+			})
+		}
+	}
+	shuffled := append([]Result(nil), results...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	writer := &collectingWriter{}
+	sorter := NewSorter(writer, compare)
+	sorter.SetBufferSize(64)
+	run := &ScanRun{}
+
+	require.NoError(t, sorter.Begin(run))
+	for _, r := range shuffled {
+		require.NoError(t, sorter.WriteResult(r))
+	}
+	assert.NotEmpty(t, sorter.spills)
+	require.NoError(t, sorter.End(run))
+
+	assert.Equal(t, results, writer.results)
+
+	leftover, err := filepath.Glob(filepath.Join(tmp, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftover)
+	_, err = os.Stat(tmp)
+	assert.NoError(t, err)
+}
+
+func TestSorter_CloseRemovesSpills(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	sorter := NewSorter(&collectingWriter{}, func(a, b Result) int { return a.Port - b.Port })
+	sorter.SetBufferSize(1)
+	require.NoError(t, sorter.Begin(&ScanRun{}))
+	require.NoError(t, sorter.WriteResult(Result{Port: 2}))
+	require.NoError(t, sorter.WriteResult(Result{Port: 1}))
+
+	spills, err := filepath.Glob(filepath.Join(tmp, "*"))
+	require.NoError(t, err)
+	assert.Len(t, spills, 2)
+
+	// The scan stopped without End, as when writing fails.
+	require.NoError(t, sorter.Close())
+	spills, err = filepath.Glob(filepath.Join(tmp, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, spills)
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+	"github.com/theryanhowell/network-scanner/pkg/stats"
+)
+
+// Stream writes scan results to writer as they arrive. Results that do not
+// pass show are not written, but still count towards completing their host
+// and towards the run's Summary. When run.PortCount is set and writer is a
+// HostWriter, EndHost is called as soon as every port on a host has been
+// scanned. If writing fails, the rest of results is received and discarded
+// in the background, so that whatever sends them is not blocked.
+func Stream(writer OutputWriter, run *ScanRun, results <-chan scanner.Port, show Filter) (err error) {
+	defer func() {
+		if err != nil {
+			go func() {
+				for range results {
+				}
+			}()
+		}
+	}()
+
+	if err := writer.Begin(run); err != nil {
+		return err
+	}
+
+	hostWriter, _ := writer.(HostWriter)
+	remaining := make(map[string]int)
+	collector := stats.NewCollector()
+
+	for port := range results {
+		collector.Add(port)
+		if result := NewResult(port); show == nil || show(result) {
+			if err := writer.WriteResult(result); err != nil {
+				return err
+			}
+		}
+
+		if hostWriter == nil || run.PortCount == 0 {
+			continue
+		}
+		if _, ok := remaining[port.Host]; !ok {
+			remaining[port.Host] = run.PortCount
+		}
+		remaining[port.Host]--
+		if remaining[port.Host] == 0 {
+			if err := hostWriter.EndHost(port.Host); err != nil {
+				return err
+			}
+		}
+	}
+
+	run.End = time.Now()
+	run.Summary = collector.Summary(run.Elapsed())
+	return writer.End(run)
+}
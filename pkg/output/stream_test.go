@@ -0,0 +1,86 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// recordingWriter records the calls made to it.
+type recordingWriter struct {
+	calls []string
+}
+
+func (w *recordingWriter) Begin(run *ScanRun) error {
+	w.calls = append(w.calls, "begin")
+	return nil
+}
+
+func (w *recordingWriter) WriteResult(r Result) error {
+	w.calls = append(w.calls, "result "+r.Host)
+	return nil
+}
+
+func (w *recordingWriter) EndHost(host string) error {
+	w.calls = append(w.calls, "end "+host)
+	return nil
+}
+
+func (w *recordingWriter) End(run *ScanRun) error {
+	w.calls = append(w.calls, "end")
+	return nil
+}
+
+func TestStream(t *testing.T) {
+	results := make(chan scanner.Port, 4)
+	results <- scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open}
+	results <- scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Closed}
+	results <- scanner.Port{Host: "10.0.0.1", Port: 80, Status: scanner.Closed}
+	results <- scanner.Port{Host: "10.0.0.2", Port: 80, Status: scanner.Open}
+	close(results)
+
+	writer := &recordingWriter{}
+	run := &ScanRun{PortCount: 2}
+	onlyOpen := StatusFilter(scanner.Open)
+
+	require.NoError(t, Stream(writer, run, results, onlyOpen))
+
+	assert.Equal(t, []string{
+		"begin",
+		"result 10.0.0.1",
+		"end 10.0.0.1",
+		"result 10.0.0.2",
+		"end 10.0.0.2",
+		"end",
+	}, writer.calls)
+	assert.False(t, run.End.IsZero())
+	require.NotNil(t, run.Summary)
+	assert.Equal(t, 2, run.Summary.Hosts)
+	assert.Equal(t, 2, run.Summary.Open)
+	assert.Equal(t, 2, run.Summary.Closed)
+}
+
+func TestStream_WriteErrorDrainsResults(t *testing.T) {
+	results := make(chan scanner.Port)
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		defer close(results)
+		for port := 1; port <= 3; port++ {
+			results <- scanner.Port{Host: "10.0.0.1", Port: port}
+		}
+	}()
+
+	err := Stream(NewCsvWriter(failingWriter{}), &ScanRun{}, results, nil)
+	assert.ErrorContains(t, err, "disk full")
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("results were not drained after the write error")
+	}
+}
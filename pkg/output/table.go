@@ -3,55 +3,262 @@ package output
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// tableColumn describes a single column of a TableWriter.
+type tableColumn struct {
+	header string
+	// rightAlign is set for numeric columns.
+	rightAlign bool
+	// flexible columns absorb the space left over by the others and are
+	// wrapped or ellipsized to fit. Other columns are never cut short.
+	flexible bool
+	// width is the streaming width used when results are not buffered.
+	width int
+}
+
+var (
+	hostColumn   = tableColumn{header: "IP Address", width: len("255.255.255.255")}
+	portColumn   = tableColumn{header: "Port", rightAlign: true, width: len("65535")}
+	statusColumn = tableColumn{header: "Status", width: len("Timed Out")}
+	bannerColumn = tableColumn{header: "Banner", flexible: true}
+)
+
+// ipv6HostWidth is the width of the longest textual IPv6 address.
+const ipv6HostWidth = len("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+
+// minFlexibleWidth is the narrowest a flexible column is squeezed to.
+const minFlexibleWidth = 10
+
 // TableWriter writes data in a table format.
 type TableWriter struct {
-	writer  io.Writer
-	headers []string
-	widths  []int
+	writer   io.Writer
+	columns  []tableColumn
+	widths   []int
+	fixed    []int
+	maxWidth int
+	buffered bool
+	wrap     bool
+	rows     [][]string
 }
 
 // NewTableWriter creates a new TableWriter.
-func NewTableWriter(writer io.Writer, headers []string) *TableWriter {
+func NewTableWriter(writer io.Writer) *TableWriter {
+	columns := []tableColumn{hostColumn, portColumn, statusColumn}
 	return &TableWriter{
 		writer:  writer,
-		headers: headers,
-		widths:  make([]int, len(headers)),
+		columns: columns,
+		widths:  make([]int, len(columns)),
 	}
 }
 
-// SetWidths sets fixed column widths.
+// SetWidths sets fixed column widths. Columns without a width, or with a
+// width of zero, are sized automatically.
 func (t *TableWriter) SetWidths(widths []int) {
 	t.widths = widths
+	t.fixed = widths
+}
+
+// SetMaxWidth limits the width of a line, usually to the width of the
+// terminal. Flexible columns are shrunk to fit. Zero means no limit.
+func (t *TableWriter) SetMaxWidth(width int) {
+	t.maxWidth = width
+}
+
+// SetBuffered holds all rows until End so that columns can be sized to fit
+// the data instead of being estimated up front.
+func (t *TableWriter) SetBuffered(buffered bool) {
+	t.buffered = buffered
+}
+
+// SetWrap wraps cells that are too long for a flexible column onto
+// continuation lines instead of ellipsizing them.
+func (t *TableWriter) SetWrap(wrap bool) {
+	t.wrap = wrap
+}
+
+// SetShowBanner adds a column with the banner of each open port.
+func (t *TableWriter) SetShowBanner(show bool) {
+	t.columns = []tableColumn{hostColumn, portColumn, statusColumn}
+	if show {
+		t.columns = append(t.columns, bannerColumn)
+	}
 }
 
-// PrintHeader prints the table header.
-func (t *TableWriter) PrintHeader() {
-	if t.widths[0] == 0 {
-		for i, h := range t.headers {
-			t.widths[i] = len(h)
+// Begin prints the table header, or starts buffering rows.
+func (t *TableWriter) Begin(run *ScanRun) error {
+	t.rows = nil
+	if t.buffered {
+		return nil
+	}
+
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = max(c.width, len(c.header))
+		if i == 0 && hasIPv6Target(run.Targets) {
+			widths[i] = ipv6HostWidth
 		}
 	}
-	for i, header := range t.headers {
-		fmt.Fprintf(t.writer, "%-*s", t.widths[i]+2, header)
+	t.fitWidths(widths)
+	return t.printHeader()
+}
+
+// WriteResult prints a single row, or buffers it until End.
+func (t *TableWriter) WriteResult(r Result) error {
+	row := []string{r.Host, strconv.Itoa(r.Port), r.Status.String()}
+	if len(t.columns) > len(row) {
+		row = append(row, r.Banner)
+	}
+
+	if t.buffered {
+		t.rows = append(t.rows, row)
+		return nil
 	}
-	fmt.Fprintln(t.writer)
+	return t.printRow(row)
+}
 
-	for i := range t.headers {
-		fmt.Fprintf(t.writer, "%s", strings.Repeat("-", t.widths[i]+2))
+// End prints the buffered table, if results were buffered.
+func (t *TableWriter) End(run *ScanRun) error {
+	if !t.buffered {
+		return nil
+	}
+
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = len(c.header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	t.fitWidths(widths)
+
+	if err := t.printHeader(); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := t.printRow(row); err != nil {
+			return err
+		}
+	}
+	t.rows = nil
+	return nil
+}
+
+// fitWidths applies any fixed widths over the computed ones, then shrinks
+// flexible columns until the line fits in the maximum width.
+func (t *TableWriter) fitWidths(widths []int) {
+	for i := range widths {
+		if t.isFixed(i) {
+			widths[i] = t.fixed[i]
+		}
+	}
+
+	if t.maxWidth > 0 {
+		total := 0
+		for _, w := range widths {
+			total += w + 2
+		}
+		for i := len(widths) - 1; i >= 0 && total > t.maxWidth; i-- {
+			if !t.columns[i].flexible {
+				continue
+			}
+			shrunk := max(widths[i]-(total-t.maxWidth), minFlexibleWidth, len(t.columns[i].header))
+			total -= widths[i] - shrunk
+			widths[i] = shrunk
+		}
+	}
+
+	t.widths = widths
+}
+
+// isFixed reports whether column i was given a width by SetWidths.
+func (t *TableWriter) isFixed(i int) bool {
+	return i < len(t.fixed) && t.fixed[i] > 0
+}
+
+// printHeader prints the column headers and a separator line.
+func (t *TableWriter) printHeader() error {
+	headers := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		headers[i] = c.header
+	}
+	if err := t.printRow(headers); err != nil {
+		return err
+	}
+
+	var separator strings.Builder
+	for _, w := range t.widths {
+		separator.WriteString(strings.Repeat("-", w+2))
+	}
+	_, err := fmt.Fprintln(t.writer, separator.String())
+	return err
+}
+
+// printRow prints cells padded to the column widths. Cells too long for a
+// flexible column are wrapped or ellipsized if the line has a maximum width
+// or the column a fixed one; other columns are printed in full even if that
+// pushes the rest of the row out of line.
+func (t *TableWriter) printRow(cells []string) error {
+	var lines strings.Builder
+	for len(cells) > 0 {
+		var overflow []string
+		for i, cell := range cells {
+			width := utf8.RuneCountInString(cell)
+			if i < len(t.widths) {
+				width = t.widths[i]
+			}
+
+			limited := t.maxWidth > 0 || t.isFixed(i)
+			if i < len(t.columns) && t.columns[i].flexible && limited && utf8.RuneCountInString(cell) > width {
+				var rest string
+				cell, rest = t.cut(cell, width)
+				if rest != "" {
+					if overflow == nil {
+						overflow = make([]string, len(cells))
+					}
+					overflow[i] = rest
+				}
+			}
+
+			if i < len(t.columns) && t.columns[i].rightAlign {
+				fmt.Fprintf(&lines, "%*s  ", width, cell)
+			} else {
+				fmt.Fprintf(&lines, "%-*s", width+2, cell)
+			}
+		}
+		lines.WriteString("\n")
+		cells = overflow
+	}
+
+	_, err := io.WriteString(t.writer, lines.String())
+	return err
+}
+
+// cut shortens a cell to width runes. When wrapping, the remainder is
+// returned so it can be printed on the next line; otherwise the cell is
+// ellipsized.
+func (t *TableWriter) cut(cell string, width int) (string, string) {
+	runes := []rune(cell)
+	if width < 1 {
+		return "", ""
+	}
+	if t.wrap {
+		return string(runes[:width]), string(runes[width:])
 	}
-	fmt.Fprintln(t.writer)
+	return string(runes[:width-1]) + "…", ""
 }
 
-// PrintRow prints a single row.
-func (t *TableWriter) PrintRow(row []string) {
-	for i, cell := range row {
-		if len(cell) > t.widths[i] {
-			cell = cell[:t.widths[i]]
+// hasIPv6Target reports whether any target is an IPv6 address or range.
+func hasIPv6Target(targets []string) bool {
+	for _, target := range targets {
+		if strings.Contains(target, ":") {
+			return true
 		}
-		fmt.Fprintf(t.writer, "%-*s", t.widths[i]+2, cell)
 	}
-	fmt.Fprintln(t.writer)
+	return false
 }
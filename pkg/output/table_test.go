@@ -7,93 +7,192 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
 )
 
 func TestNewTableWriter(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"h1", "h2"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 
 	assert.NotNil(t, tw)
 	assert.Equal(t, writer, tw.writer)
-	assert.Equal(t, headers, tw.headers)
+	assert.Equal(t, []tableColumn{hostColumn, portColumn, statusColumn}, tw.columns)
 	assert.NotNil(t, tw.widths)
-	assert.Equal(t, len(headers), len(tw.widths))
+	assert.Equal(t, len(tw.columns), len(tw.widths))
 }
 
 func TestTableWriter_SetWidths(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"h1", "h2"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 
-	widths := []int{10, 20}
+	widths := []int{10, 20, 30}
 	tw.SetWidths(widths)
 
 	assert.Equal(t, widths, tw.widths)
 }
 
-func TestTableWriter_PrintHeader_AutoSize(t *testing.T) {
+func TestTableWriter_Begin_AutoSize(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"IP", "Port", "Status"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 
-	tw.PrintHeader()
+	assert.NoError(t, tw.Begin(&ScanRun{}))
 
-	expectedHeader := fmt.Sprintf("%-*s%-*s%-*s\n", 4, "IP", 6, "Port", 8, "Status")
-	expectedSeparator := fmt.Sprintf("%s%s%s\n", strings.Repeat("-", 4), strings.Repeat("-", 6), strings.Repeat("-", 8))
+	expectedHeader := fmt.Sprintf("%-*s%*s  %-*s\n", 17, "IP Address", 5, "Port", 11, "Status")
+	expectedSeparator := fmt.Sprintf("%s%s%s\n", strings.Repeat("-", 17), strings.Repeat("-", 7), strings.Repeat("-", 11))
 	expected := expectedHeader + expectedSeparator
 	assert.Equal(t, expected, writer.String())
 }
 
-func TestTableWriter_PrintHeader_WithWidths(t *testing.T) {
+func TestTableWriter_Begin_IPv6(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+
+	assert.NoError(t, tw.Begin(&ScanRun{Targets: []string{"2001:db8::/120"}}))
+	assert.Equal(t, ipv6HostWidth, tw.widths[0])
+}
+
+func TestTableWriter_Begin_WithWidths(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"IP", "Port", "Status"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 	widths := []int{15, 5, 8}
 	tw.SetWidths(widths)
 
-	tw.PrintHeader()
+	assert.NoError(t, tw.Begin(&ScanRun{}))
 
-	expectedHeader := fmt.Sprintf("%-*s%-*s%-*s\n", 17, "IP", 7, "Port", 10, "Status")
+	expectedHeader := fmt.Sprintf("%-*s%*s  %-*s\n", 17, "IP Address", 5, "Port", 10, "Status")
 	expectedSeparator := fmt.Sprintf("%s%s%s\n", strings.Repeat("-", 17), strings.Repeat("-", 7), strings.Repeat("-", 10))
 	expected := expectedHeader + expectedSeparator
 	assert.Equal(t, expected, writer.String())
 }
 
-func TestTableWriter_PrintRow(t *testing.T) {
+func TestTableWriter_Begin_ShortWidths(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetWidths([]int{12})
+
+	assert.NotPanics(t, func() {
+		assert.NoError(t, tw.Begin(&ScanRun{}))
+		assert.NoError(t, tw.WriteResult(Result{Host: "127.0.0.1", Port: 80, Status: scanner.Open}))
+	})
+
+	assert.Equal(t, []int{12, 5, 9}, tw.widths)
+}
+
+func TestTableWriter_WriteResult(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"IP", "Port", "Status"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 	widths := []int{15, 5, 8}
 	tw.SetWidths(widths)
 
-	row := []string{"127.0.0.1", "80", "Open"}
-	tw.PrintRow(row)
+	assert.NoError(t, tw.WriteResult(Result{Host: "127.0.0.1", Port: 80, Status: scanner.Open}))
 
-	expected := fmt.Sprintf("%-*s%-*s%-*s\n", 17, "127.0.0.1", 7, "80", 10, "Open")
+	expected := fmt.Sprintf("%-*s%*s  %-*s\n", 17, "127.0.0.1", 5, "80", 10, "Open")
 	assert.Equal(t, expected, writer.String())
 }
 
 func TestTableWriter_FullTable(t *testing.T) {
 	writer := &bytes.Buffer{}
-	headers := []string{"IP", "Port", "Status"}
-	tw := NewTableWriter(writer, headers)
+	tw := NewTableWriter(writer)
 	widths := []int{15, 5, 10}
 	tw.SetWidths(widths)
+	run := &ScanRun{}
 
-	tw.PrintHeader()
-	tw.PrintRow([]string{"127.0.0.1", "80", "Open"})
-	tw.PrintRow([]string{"127.0.0.1", "443", "Closed"})
-	tw.PrintRow([]string{"255.255.255.255", "12345", "Timed Out"})
+	assert.NoError(t, tw.Begin(run))
+	assert.NoError(t, tw.WriteResult(Result{Host: "127.0.0.1", Port: 80, Status: scanner.Open}))
+	assert.NoError(t, tw.WriteResult(Result{Host: "127.0.0.1", Port: 443, Status: scanner.Closed}))
+	assert.NoError(t, tw.WriteResult(Result{Host: "255.255.255.255", Port: 12345, Status: scanner.Timeout}))
+	assert.NoError(t, tw.End(run))
 
 	var expected strings.Builder
-	expectedHeader := fmt.Sprintf("%-*s%-*s%-*s\n", 17, "IP", 7, "Port", 12, "Status")                                     // Changed from 10 to 12
-	expectedSeparator := fmt.Sprintf("%s%s%s\n", strings.Repeat("-", 17), strings.Repeat("-", 7), strings.Repeat("-", 12)) // Changed from 10 to 12
+	expectedHeader := fmt.Sprintf("%-*s%*s  %-*s\n", 17, "IP Address", 5, "Port", 12, "Status")
+	expectedSeparator := fmt.Sprintf("%s%s%s\n", strings.Repeat("-", 17), strings.Repeat("-", 7), strings.Repeat("-", 12))
 	expected.WriteString(expectedHeader)
 	expected.WriteString(expectedSeparator)
-	expected.WriteString(fmt.Sprintf("%-*s%-*s%-*s\n", 17, "127.0.0.1", 7, "80", 12, "Open"))               // Changed from 10 to 12
-	expected.WriteString(fmt.Sprintf("%-*s%-*s%-*s\n", 17, "127.0.0.1", 7, "443", 12, "Closed"))            // Changed from 10 to 12
-	expected.WriteString(fmt.Sprintf("%-*s%-*s%-*s\n", 17, "255.255.255.255", 7, "12345", 12, "Timed Out")) // Changed from 10 to 12
+	expected.WriteString(fmt.Sprintf("%-*s%*s  %-*s\n", 17, "127.0.0.1", 5, "80", 12, "Open"))
+	expected.WriteString(fmt.Sprintf("%-*s%*s  %-*s\n", 17, "127.0.0.1", 5, "443", 12, "Closed"))
+	expected.WriteString(fmt.Sprintf("%-*s%*s  %-*s\n", 17, "255.255.255.255", 5, "12345", 12, "Timed Out"))
 
 	assert.Equal(t, expected.String(), writer.String())
 }
+
+func TestTableWriter_NeverCutsHosts(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetWidths([]int{10, 5, 9})
+
+	host := "2001:db8:85a3::8a2e:370:7334"
+	assert.NoError(t, tw.WriteResult(Result{Host: host, Port: 443, Status: scanner.Open}))
+
+	assert.Contains(t, writer.String(), host)
+}
+
+func TestTableWriter_Buffered(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetBuffered(true)
+	run := &ScanRun{}
+
+	assert.NoError(t, tw.Begin(run))
+	assert.NoError(t, tw.WriteResult(Result{Host: "2001:db8:85a3::8a2e:370:7334", Port: 22, Status: scanner.Open}))
+	assert.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 8080, Status: scanner.Closed}))
+	assert.Empty(t, writer.String())
+	assert.NoError(t, tw.End(run))
+
+	expected := "IP Address                    Port  Status  \n" +
+		"--------------------------------------------\n" +
+		"2001:db8:85a3::8a2e:370:7334    22  Open    \n" +
+		"10.0.0.1                      8080  Closed  \n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestTableWriter_BannerEllipsized(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetShowBanner(true)
+	tw.SetBuffered(true)
+	tw.SetMaxWidth(45)
+	run := &ScanRun{}
+
+	assert.NoError(t, tw.Begin(run))
+	assert.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Banner: "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13"}))
+	assert.NoError(t, tw.End(run))
+
+	lines := strings.Split(writer.String(), "\n")
+	assert.Equal(t, "10.0.0.1      22  Open    SSH-2.0-OpenSSH_…  ", lines[2])
+	assert.Equal(t, 45, len([]rune(lines[2])))
+}
+
+func TestTableWriter_BannerWrapped(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetShowBanner(true)
+	tw.SetWidths([]int{8, 4, 6, 10})
+	tw.SetWrap(true)
+
+	assert.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Banner: "SSH-2.0-OpenSSH_9.6p1"}))
+
+	expected := "10.0.0.1    22  Open    SSH-2.0-Op  \n" +
+		"                        enSSH_9.6p  \n" +
+		"                        1           \n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestTableWriter_BannerStreamedUnlimited(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw := NewTableWriter(writer)
+	tw.SetShowBanner(true)
+
+	assert.NoError(t, tw.Begin(&ScanRun{}))
+	assert.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Banner: "SSH-2.0-OpenSSH_9.6p1"}))
+
+	lines := strings.Split(writer.String(), "\n")
+	assert.Equal(t, "10.0.0.1            22  Open       SSH-2.0-OpenSSH_9.6p1", lines[2])
+}
+
+func TestTableWriter_WriteError(t *testing.T) {
+	tw := NewTableWriter(failingWriter{})
+
+	assert.Error(t, tw.Begin(&ScanRun{}))
+	assert.Error(t, tw.WriteResult(Result{Host: "127.0.0.1", Port: 80}))
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are the helper functions available to user templates.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Microsecond).String()
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// TemplateFuncs returns the helper functions available to user templates,
+// so that other templates offer the same ones.
+func TemplateFuncs() template.FuncMap {
+	funcs := make(template.FuncMap, len(templateFuncs))
+	for name, f := range templateFuncs {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// TemplateWriter writes each result using a user supplied text/template.
+//
+// The template is executed once per result with the Result as data. If the
+// template defines "header" or "footer" blocks they are executed with the
+// ScanRun before the first and after the last result. A newline is added
+// after each result unless the template output already ends with one.
+type TemplateWriter struct {
+	writer   io.Writer
+	template *template.Template
+}
+
+// NewTemplateWriter parses text and creates a new TemplateWriter.
+func NewTemplateWriter(writer io.Writer, text string) (*TemplateWriter, error) {
+	tmpl, err := template.New("result").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateWriter{
+		writer:   writer,
+		template: tmpl,
+	}, nil
+}
+
+// Begin executes the header block, if any.
+func (t *TemplateWriter) Begin(run *ScanRun) error {
+	return t.executeBlock("header", run)
+}
+
+// WriteResult executes the template for a single result.
+func (t *TemplateWriter) WriteResult(r Result) error {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, r); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err := t.writer.Write(buf.Bytes())
+	return err
+}
+
+// End executes the footer block, if any.
+func (t *TemplateWriter) End(run *ScanRun) error {
+	return t.executeBlock("footer", run)
+}
+
+// executeBlock executes a named block if the template defines it.
+func (t *TemplateWriter) executeBlock(name string, run *ScanRun) error {
+	if t.template.Lookup(name) == nil {
+		return nil
+	}
+	return t.template.ExecuteTemplate(t.writer, name, run)
+}
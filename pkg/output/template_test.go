@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestTemplateWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw, err := NewTemplateWriter(writer, "{{.Host}}:{{.Port}} {{upper .Status.String}}")
+	require.NoError(t, err)
+
+	run := &ScanRun{}
+	require.NoError(t, tw.Begin(run))
+	require.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open}))
+	require.NoError(t, tw.WriteResult(Result{Host: "10.0.0.2", Port: 80, Status: scanner.Timeout}))
+	require.NoError(t, tw.End(run))
+
+	assert.Equal(t, "10.0.0.1:22 OPEN\n10.0.0.2:80 TIMED OUT\n", writer.String())
+}
+
+func TestTemplateWriter_HeaderAndFooter(t *testing.T) {
+	text := `{{define "header"}}# {{join .Targets " "}} ports {{.Ports}}
+{{end}}{{define "footer"}}# done in {{duration .Elapsed}}
+{{end}}{{.Host}} {{duration .Latency}}
+`
+	writer := &bytes.Buffer{}
+	tw, err := NewTemplateWriter(writer, text)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{Targets: []string{"10.0.0.0/30", "10.0.1.0/30"}, Ports: "22", Start: start}
+	require.NoError(t, tw.Begin(run))
+	require.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Latency: 1234567 * time.Nanosecond}))
+	run.End = start.Add(3 * time.Second)
+	require.NoError(t, tw.End(run))
+
+	assert.Equal(t, "# 10.0.0.0/30 10.0.1.0/30 ports 22\n10.0.0.1 1.235ms\n# done in 3s\n", writer.String())
+}
+
+func TestTemplateWriter_Json(t *testing.T) {
+	writer := &bytes.Buffer{}
+	tw, err := NewTemplateWriter(writer, "{{json .}}")
+	require.NoError(t, err)
+
+	require.NoError(t, tw.WriteResult(Result{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Service: "ssh"}))
+
+	assert.Equal(t, `{"host":"10.0.0.1","port":22,"protocol":"tcp","status":"open","latency_ms":0,"service":"ssh"}`+"\n", writer.String())
+}
+
+func TestTemplateWriter_Errors(t *testing.T) {
+	_, err := NewTemplateWriter(&bytes.Buffer{}, "{{.Host")
+	assert.Error(t, err)
+
+	tw, err := NewTemplateWriter(&bytes.Buffer{}, "{{.Missing}}")
+	require.NoError(t, err)
+	assert.Error(t, tw.WriteResult(Result{}))
+}
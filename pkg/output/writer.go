@@ -1,7 +1,26 @@
 package output
 
-// OutputWriter is an interface for writing data in different formats.
+// OutputWriter is an interface for writing scan results in different formats.
+//
+// Begin is called once before any results, WriteResult once per result and
+// End once after the last result. The run passed to End has its End time set.
 type OutputWriter interface {
-	PrintHeader()
-	PrintRow(row []string)
+	Begin(run *ScanRun) error
+	WriteResult(r Result) error
+	End(run *ScanRun) error
+}
+
+// HostWriter is implemented by writers that group results by host. EndHost
+// is called once every port on a host has been scanned, so the host can be
+// written without waiting for the rest of the scan.
+type HostWriter interface {
+	EndHost(host string) error
+}
+
+// SkipWriter is implemented by writers that need to know about the results
+// a filter kept from them. SkipResult is called instead of WriteResult for
+// each of those results, so that, for example, a host can be shown as up
+// even if none of its ports are.
+type SkipWriter interface {
+	SkipResult(r Result) error
 }
@@ -0,0 +1,257 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// nmapTimeFormat is the layout nmap uses for its human readable timestamps.
+const nmapTimeFormat = "Mon Jan 2 15:04:05 2006"
+
+type nmapRun struct {
+	XMLName          xml.Name     `xml:"nmaprun"`
+	Scanner          string       `xml:"scanner,attr"`
+	Args             string       `xml:"args,attr"`
+	Start            int64        `xml:"start,attr"`
+	StartStr         string       `xml:"startstr,attr"`
+	XMLOutputVersion string       `xml:"xmloutputversion,attr"`
+	ScanInfo         nmapScanInfo `xml:"scaninfo"`
+	Hosts            []nmapHost   `xml:"host"`
+	RunStats         nmapRunStats `xml:"runstats"`
+}
+
+type nmapScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+type nmapHost struct {
+	Status  nmapStatus  `xml:"status"`
+	Address nmapAddress `xml:"address"`
+	Ports   []nmapPort  `xml:"ports>port"`
+}
+
+type nmapStatus struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapPort struct {
+	Protocol string       `xml:"protocol,attr"`
+	PortID   int          `xml:"portid,attr"`
+	State    nmapState    `xml:"state"`
+	Service  *nmapService `xml:"service"`
+	Scripts  []nmapScript `xml:"script"`
+}
+
+type nmapState struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type nmapService struct {
+	Name   string `xml:"name,attr"`
+	Method string `xml:"method,attr"`
+	Conf   int    `xml:"conf,attr"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+type nmapRunStats struct {
+	Finished nmapFinished  `xml:"finished"`
+	Hosts    nmapHostStats `xml:"hosts"`
+}
+
+type nmapFinished struct {
+	Time    int64   `xml:"time,attr"`
+	TimeStr string  `xml:"timestr,attr"`
+	Elapsed float64 `xml:"elapsed,attr"`
+	Summary string  `xml:"summary,attr"`
+	Exit    string  `xml:"exit,attr"`
+}
+
+type nmapHostStats struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+// XmlWriter writes results as an nmap compatible XML document, grouping
+// ports under the host they belong to.
+type XmlWriter struct {
+	writer io.Writer
+	hosts  *hostGroups
+	// up holds the reason each host that answered is up, from every
+	// result including those filtered out.
+	up map[string]string
+}
+
+// NewXmlWriter creates a new XmlWriter.
+func NewXmlWriter(writer io.Writer) *XmlWriter {
+	return &XmlWriter{
+		writer: writer,
+		hosts:  newHostGroups(),
+		up:     make(map[string]string),
+	}
+}
+
+// Begin starts a new document.
+func (x *XmlWriter) Begin(run *ScanRun) error {
+	x.hosts = newHostGroups()
+	x.up = make(map[string]string)
+	return nil
+}
+
+// WriteResult buffers a single result under its host.
+func (x *XmlWriter) WriteResult(r Result) error {
+	x.hosts.add(r)
+	x.answered(r)
+	return nil
+}
+
+// SkipResult records whether the host of a result that is not written is
+// up, so that a host is listed as up even if all its ports are filtered out.
+func (x *XmlWriter) SkipResult(r Result) error {
+	x.answered(r)
+	return nil
+}
+
+// answered marks the host of r as up if its port answered. An open port
+// takes precedence over a refused one as the reason; a host that could not
+// be reached is not up.
+func (x *XmlWriter) answered(r Result) {
+	switch {
+	case r.Status == scanner.Open:
+		x.up[r.Host] = "syn-ack"
+	case r.Answered() && x.up[r.Host] == "":
+		x.up[r.Host] = "conn-refused"
+	}
+}
+
+// End writes the complete document.
+func (x *XmlWriter) End(run *ScanRun) error {
+	doc := nmapRun{
+		Scanner:          "network-scanner",
+		Args:             run.Command,
+		Start:            run.Start.Unix(),
+		StartStr:         run.Start.Format(nmapTimeFormat),
+		XMLOutputVersion: "1.05",
+		ScanInfo: nmapScanInfo{
+			Type:     "connect",
+			Protocol: "tcp",
+			Services: run.Ports,
+		},
+	}
+	if ports, err := iputil.ParsePorts(run.Ports); err == nil {
+		doc.ScanInfo.NumServices = len(ports)
+	}
+
+	hosts := x.hosts.hosts()
+	for host := range x.up {
+		if _, ok := x.hosts.results[host]; !ok {
+			hosts = append(hosts, host)
+		}
+	}
+	slices.SortFunc(hosts, CompareHosts)
+
+	for _, host := range hosts {
+		doc.Hosts = append(doc.Hosts, newNmapHost(host, x.hosts.take(host), x.up[host]))
+	}
+	up := len(x.up)
+
+	total := max(run.HostCount, len(doc.Hosts))
+	elapsed := run.Elapsed()
+	doc.RunStats = nmapRunStats{
+		Finished: nmapFinished{
+			Time:    run.End.Unix(),
+			TimeStr: run.End.Format(nmapTimeFormat),
+			Elapsed: elapsed.Round(10 * time.Millisecond).Seconds(),
+			Summary: fmt.Sprintf("network-scanner done at %s; %d IP addresses (%d hosts up) scanned in %.2f seconds",
+				run.End.Format(nmapTimeFormat), total, up, elapsed.Seconds()),
+			Exit: "success",
+		},
+		Hosts: nmapHostStats{Up: up, Down: total - up, Total: total},
+	}
+
+	if _, err := io.WriteString(x.writer, xml.Header+"<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(x.writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(x.writer, "\n")
+	return err
+}
+
+// newNmapHost builds the host element for a host's results. reason is why
+// the host is up, or empty if none of its ports answered.
+func newNmapHost(host string, results []Result, reason string) nmapHost {
+	h := nmapHost{
+		Status:  nmapStatus{State: "down", Reason: "no-response"},
+		Address: nmapAddress{Addr: host, AddrType: addrType(host)},
+	}
+	if reason != "" {
+		h.Status = nmapStatus{State: "up", Reason: reason}
+	}
+
+	for _, r := range results {
+		state, reason := nmapPortState(r.Status)
+		if r.Unreachable {
+			state, reason = "filtered", "host-unreach"
+		}
+		p := nmapPort{
+			Protocol: "tcp",
+			PortID:   r.Port,
+			State:    nmapState{State: state, Reason: reason},
+		}
+		if r.Service != "" {
+			p.Service = &nmapService{Name: r.Service, Method: "table", Conf: 3}
+		}
+		if r.Banner != "" {
+			p.Scripts = []nmapScript{{ID: "banner", Output: r.Banner}}
+		}
+		h.Ports = append(h.Ports, p)
+	}
+
+	return h
+}
+
+// nmapPortState maps a status to nmap's port state and reason for a connect
+// scan.
+func nmapPortState(s scanner.Status) (string, string) {
+	switch s {
+	case scanner.Open:
+		return "open", "syn-ack"
+	case scanner.Closed:
+		return "closed", "conn-refused"
+	default:
+		return "filtered", "no-response"
+	}
+}
+
+// addrType returns nmap's address type for a host.
+func addrType(host string) string {
+	if addr, err := netip.ParseAddr(host); err == nil && addr.Is6() && !addr.Is4In6() {
+		return "ipv6"
+	}
+	return "ipv4"
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestXmlWriter(t *testing.T) {
+	writer := &bytes.Buffer{}
+	xw := NewXmlWriter(writer)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &ScanRun{
+		Command:   "network-scanner 10.0.0.0/29 22,80",
+		Targets:   []string{"10.0.0.0/29"},
+		Ports:     "22,80",
+		HostCount: 6,
+		Start:     start,
+	}
+
+	require.NoError(t, xw.Begin(run))
+	require.NoError(t, xw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, xw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open, Banner: "SSH-2.0"})))
+	require.NoError(t, xw.WriteResult(NewResult(scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Timeout})))
+	run.End = start.Add(1500 * time.Millisecond)
+	require.NoError(t, xw.End(run))
+
+	out := writer.String()
+	assert.True(t, strings.HasPrefix(out, xml.Header+"<!DOCTYPE nmaprun>\n"))
+
+	var doc nmapRun
+	require.NoError(t, xml.Unmarshal(writer.Bytes(), &doc))
+
+	assert.Equal(t, "network-scanner 10.0.0.0/29 22,80", doc.Args)
+	assert.Equal(t, start.Unix(), doc.Start)
+	assert.Equal(t, 2, doc.ScanInfo.NumServices)
+	require.Len(t, doc.Hosts, 2)
+
+	down := doc.Hosts[0]
+	assert.Equal(t, "10.0.0.2", down.Address.Addr)
+	assert.Equal(t, "ipv4", down.Address.AddrType)
+	assert.Equal(t, "down", down.Status.State)
+	assert.Equal(t, "filtered", down.Ports[0].State.State)
+
+	up := doc.Hosts[1]
+	assert.Equal(t, "10.0.0.5", up.Address.Addr)
+	assert.Equal(t, "up", up.Status.State)
+	require.Len(t, up.Ports, 2)
+	assert.Equal(t, 22, up.Ports[0].PortID)
+	assert.Equal(t, "open", up.Ports[0].State.State)
+	assert.Equal(t, "ssh", up.Ports[0].Service.Name)
+	assert.Equal(t, []nmapScript{{ID: "banner", Output: "SSH-2.0"}}, up.Ports[0].Scripts)
+	assert.Equal(t, 80, up.Ports[1].PortID)
+	assert.Equal(t, "closed", up.Ports[1].State.State)
+	assert.Equal(t, "http", up.Ports[1].Service.Name)
+
+	assert.Equal(t, nmapHostStats{Up: 1, Down: 5, Total: 6}, doc.RunStats.Hosts)
+	assert.Equal(t, 1.5, doc.RunStats.Finished.Elapsed)
+	assert.Equal(t, "success", doc.RunStats.Finished.Exit)
+}
+
+func TestXmlWriter_FilteredHosts(t *testing.T) {
+	writer := &bytes.Buffer{}
+	multi := NewMultiWriter()
+	multi.Add(NewXmlWriter(writer), StatusFilter(scanner.Open))
+
+	run := &ScanRun{Ports: "22,80", HostCount: 4}
+	require.NoError(t, multi.Begin(run))
+	require.NoError(t, multi.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 22, Status: scanner.Open})))
+	require.NoError(t, multi.WriteResult(NewResult(scanner.Port{Host: "10.0.0.5", Port: 80, Status: scanner.Closed})))
+	require.NoError(t, multi.WriteResult(NewResult(scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Closed})))
+	require.NoError(t, multi.WriteResult(NewResult(scanner.Port{Host: "10.0.0.3", Port: 22, Status: scanner.Timeout})))
+	require.NoError(t, multi.WriteResult(NewResult(scanner.Port{Host: "10.0.0.4", Port: 22, Status: scanner.Closed, Unreachable: true})))
+	require.NoError(t, multi.End(run))
+
+	var doc nmapRun
+	require.NoError(t, xml.Unmarshal(writer.Bytes(), &doc))
+	require.Len(t, doc.Hosts, 2)
+
+	closed := doc.Hosts[0]
+	assert.Equal(t, "10.0.0.2", closed.Address.Addr)
+	assert.Equal(t, nmapStatus{State: "up", Reason: "conn-refused"}, closed.Status)
+	assert.Empty(t, closed.Ports)
+
+	open := doc.Hosts[1]
+	assert.Equal(t, "10.0.0.5", open.Address.Addr)
+	assert.Equal(t, nmapStatus{State: "up", Reason: "syn-ack"}, open.Status)
+	assert.Len(t, open.Ports, 1)
+
+	assert.Equal(t, nmapHostStats{Up: 2, Down: 2, Total: 4}, doc.RunStats.Hosts)
+}
+
+func TestNewNmapHost_Unreachable(t *testing.T) {
+	h := newNmapHost("10.0.0.4", []Result{{Host: "10.0.0.4", Port: 22, Status: scanner.Closed, Unreachable: true}}, "")
+	assert.Equal(t, nmapStatus{State: "down", Reason: "no-response"}, h.Status)
+	assert.Equal(t, nmapState{State: "filtered", Reason: "host-unreach"}, h.Ports[0].State)
+}
+
+func TestAddrType(t *testing.T) {
+	assert.Equal(t, "ipv4", addrType("192.168.1.1"))
+	assert.Equal(t, "ipv6", addrType("2001:db8::1"))
+	assert.Equal(t, "ipv4", addrType("::ffff:192.168.1.1"))
+}
@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// Kind is the kind of a policy violation.
+type Kind string
+
+const (
+	// Unexpected is an open port that no matching rule allows.
+	Unexpected Kind = "unexpected"
+	// Missing is a required port that is not open.
+	Missing Kind = "missing"
+)
+
+// Violation is a port whose state does not match the policy.
+type Violation struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	Kind Kind   `json:"kind"`
+	// Rules names the rules the port was checked against.
+	Rules []string `json:"rules"`
+	// Status is the status of the port, or nil if it was not scanned.
+	Status *scanner.Status `json:"status"`
+}
+
+// String describes the violation in a single line.
+func (v Violation) String() string {
+	rules := strings.Join(v.Rules, ", ")
+	switch {
+	case v.Kind == Unexpected:
+		return fmt.Sprintf("%s port %d is open but not allowed by %s", v.Host, v.Port, rules)
+	case v.Status == nil:
+		return fmt.Sprintf("%s port %d is required by %s but was not scanned", v.Host, v.Port, rules)
+	default:
+		return fmt.Sprintf("%s port %d is required by %s but is %s", v.Host, v.Port, rules, v.Status)
+	}
+}
+
+// Evaluate checks scan results against the policy and returns the
+// violations, ordered by host and port. Only hosts that appear in the
+// results are checked.
+func (p *Policy) Evaluate(results []output.Result) []Violation {
+	statuses := map[string]map[int]scanner.Status{}
+	for _, r := range results {
+		if statuses[r.Host] == nil {
+			statuses[r.Host] = map[int]scanner.Status{}
+		}
+		statuses[r.Host][r.Port] = r.Status
+	}
+
+	violations := []Violation{}
+	for host, ports := range statuses {
+		rules := p.rulesFor(host)
+		if len(rules) == 0 {
+			continue
+		}
+		violations = append(violations, checkHost(host, ports, rules)...)
+	}
+
+	slices.SortFunc(violations, func(a, b Violation) int {
+		return cmp.Or(output.CompareHosts(a.Host, b.Host), cmp.Compare(a.Port, b.Port))
+	})
+	return violations
+}
+
+// rulesFor returns the rules that apply to host, falling back to the
+// default rule.
+func (p *Policy) rulesFor(host string) []*Rule {
+	var rules []*Rule
+	for i := range p.Rules {
+		if p.Rules[i].matches(host) {
+			rules = append(rules, &p.Rules[i])
+		}
+	}
+	if len(rules) == 0 && p.Default != nil {
+		rules = append(rules, p.Default)
+	}
+	return rules
+}
+
+// checkHost checks the ports scanned on a single host against its rules.
+func checkHost(host string, ports map[int]scanner.Status, rules []*Rule) []Violation {
+	var violations []Violation
+
+	checkAllowed := slices.ContainsFunc(rules, func(r *Rule) bool {
+		return r.Allowed != nil
+	})
+	if checkAllowed {
+		for port, status := range ports {
+			if status != scanner.Open {
+				continue
+			}
+			allowed := slices.ContainsFunc(rules, func(r *Rule) bool {
+				return r.Allowed.Contains(port)
+			})
+			if !allowed {
+				violations = append(violations, Violation{
+					Host:   host,
+					Port:   port,
+					Kind:   Unexpected,
+					Rules:  ruleNames(rules, func(r *Rule) bool { return r.Allowed != nil }),
+					Status: &status,
+				})
+			}
+		}
+	}
+
+	required := map[int][]string{}
+	for _, rule := range rules {
+		for _, r := range rule.Required {
+			for port := r.Start; port <= r.End; port++ {
+				required[port] = append(required[port], rule.Name)
+			}
+		}
+	}
+	for port, names := range required {
+		status, scanned := ports[port]
+		if scanned && status == scanner.Open {
+			continue
+		}
+		v := Violation{Host: host, Port: port, Kind: Missing, Rules: names}
+		if scanned {
+			v.Status = &status
+		}
+		violations = append(violations, v)
+	}
+
+	return violations
+}
+
+// ruleNames returns the names of the rules that match keep.
+func ruleNames(rules []*Rule, keep func(*Rule) bool) []string {
+	var names []string
+	for _, r := range rules {
+		if keep(r) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// Checker is an OutputWriter that collects results so they can be checked
+// against a policy once the scan finishes.
+type Checker struct {
+	policy  *Policy
+	results []output.Result
+}
+
+// NewChecker creates a new Checker for policy.
+func NewChecker(policy *Policy) *Checker {
+	return &Checker{policy: policy}
+}
+
+// Begin does nothing.
+func (c *Checker) Begin(run *output.ScanRun) error {
+	return nil
+}
+
+// WriteResult collects a result.
+func (c *Checker) WriteResult(r output.Result) error {
+	c.results = append(c.results, r)
+	return nil
+}
+
+// End does nothing.
+func (c *Checker) End(run *output.ScanRun) error {
+	return nil
+}
+
+// Violations evaluates the collected results against the policy.
+func (c *Checker) Violations() []Violation {
+	return c.policy.Evaluate(c.results)
+}
+
+// Hosts returns the hosts in the collected results that the policy applies
+// to, in numeric order.
+func (c *Checker) Hosts() []string {
+	seen := map[string]bool{}
+	var hosts []string
+	for _, r := range c.results {
+		if seen[r.Host] {
+			continue
+		}
+		seen[r.Host] = true
+		if len(c.policy.rulesFor(r.Host)) > 0 {
+			hosts = append(hosts, r.Host)
+		}
+	}
+	slices.SortFunc(hosts, output.CompareHosts)
+	return hosts
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+tags:
+  web: [10.0.0.2]
+rules:
+  - name: ssh
+    match: [10.0.0.0/24]
+    allowed: [22]
+  - name: web
+    match: [tag:web]
+    allowed: [80, 443]
+    required: [443]
+`
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	violations := p.Evaluate([]output.Result{
+		{Host: "10.0.0.1", Port: 22, Status: scanner.Open},
+		{Host: "10.0.0.1", Port: 80, Status: scanner.Open},
+		{Host: "10.0.0.2", Port: 22, Status: scanner.Open},
+		{Host: "10.0.0.2", Port: 80, Status: scanner.Open},
+		{Host: "10.0.0.2", Port: 3389, Status: scanner.Closed},
+		{Host: "10.0.0.3", Port: 443, Status: scanner.Timeout},
+		{Host: "192.168.0.1", Port: 3389, Status: scanner.Open},
+	})
+
+	require.Len(t, violations, 2)
+	assert.Equal(t, "10.0.0.1 port 80 is open but not allowed by ssh", violations[0].String())
+	assert.Equal(t, "10.0.0.2 port 443 is required by web but was not scanned", violations[1].String())
+	assert.Equal(t, Missing, violations[1].Kind)
+	assert.Nil(t, violations[1].Status)
+}
+
+func TestEvaluate_Default(t *testing.T) {
+	p, err := Parse([]byte("default:\n  allowed: []\n  required: [22]\n"))
+	require.NoError(t, err)
+
+	violations := p.Evaluate([]output.Result{
+		{Host: "10.0.0.1", Port: 22, Status: scanner.Timeout},
+		{Host: "10.0.0.1", Port: 80, Status: scanner.Open},
+	})
+
+	require.Len(t, violations, 2)
+	assert.Equal(t, "10.0.0.1 port 22 is required by default but is Timed Out", violations[0].String())
+	assert.Equal(t, Unexpected, violations[1].Kind)
+}
+
+func TestChecker(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	checker := NewChecker(p)
+	run := &output.ScanRun{}
+	require.NoError(t, output.Stream(checker, run, results(
+		scanner.Port{Host: "10.0.0.2", Port: 443, Status: scanner.Open},
+		scanner.Port{Host: "10.0.0.10", Port: 25, Status: scanner.Open},
+		scanner.Port{Host: "172.16.0.1", Port: 25, Status: scanner.Open},
+	), nil))
+
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.10"}, checker.Hosts())
+	violations := checker.Violations()
+	require.Len(t, violations, 1)
+	assert.Equal(t, "10.0.0.10", violations[0].Host)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	status := scanner.Open
+	var buf bytes.Buffer
+	err := WriteJUnit(&buf, []string{"10.0.0.1", "10.0.0.2"}, []Violation{
+		{Host: "10.0.0.2", Port: 23, Kind: Unexpected, Rules: []string{"ssh"}, Status: &status},
+	})
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1">
+  <testsuite name="network-scanner policy" tests="2" failures="1">
+    <testcase classname="policy" name="10.0.0.1"></testcase>
+    <testcase classname="policy" name="10.0.0.2">
+      <failure message="1 policy violation(s)" type="PolicyViolation">10.0.0.2 port 23 is open but not allowed by ssh</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func results(ports ...scanner.Port) <-chan scanner.Port {
+	ch := make(chan scanner.Port, len(ports))
+	for _, p := range ports {
+		ch <- p
+	}
+	close(ch)
+	return ch
+}
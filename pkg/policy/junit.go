@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the violations as a JUnit XML report, so CI systems can
+// display them. Every checked host is a test case, failing if it has any
+// violations.
+func WriteJUnit(w io.Writer, hosts []string, violations []Violation) error {
+	byHost := map[string][]Violation{}
+	for _, v := range violations {
+		byHost[v.Host] = append(byHost[v.Host], v)
+	}
+
+	suite := junitTestSuite{Name: "network-scanner policy"}
+	for _, host := range hosts {
+		tc := junitTestCase{ClassName: "policy", Name: host}
+		if hostViolations := byHost[host]; len(hostViolations) > 0 {
+			lines := make([]string, len(hostViolations))
+			for i, v := range hostViolations {
+				lines[i] = v.String()
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d policy violation(s)", len(hostViolations)),
+				Type:    "PolicyViolation",
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	report := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,170 @@
+package policy
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy declares which ports may and must be open on which hosts.
+type Policy struct {
+	// Tags name groups of hosts and CIDR ranges that rules can refer to as
+	// "tag:<name>".
+	Tags map[string][]string `yaml:"tags" json:"tags"`
+	// Rules apply to the hosts they match. A host matched by several rules
+	// may have any port allowed by one of them open, and must have every
+	// port required by any of them open.
+	Rules []Rule `yaml:"rules" json:"rules"`
+	// Default applies to hosts that no rule matches. Hosts matching no rule
+	// are not checked if it is not set.
+	Default *Rule `yaml:"default" json:"default"`
+}
+
+// Rule is a set of allowed and required open ports for some hosts.
+type Rule struct {
+	Name string `yaml:"name" json:"name"`
+	// Match lists the IP addresses, CIDR ranges and "tag:<name>" references
+	// the rule applies to.
+	Match []string `yaml:"match" json:"match"`
+	// Allowed lists the ports that may be open. Open ports are not checked
+	// if it is not set.
+	Allowed Ports `yaml:"allowed" json:"allowed"`
+	// Required lists the ports that must be open.
+	Required Ports `yaml:"required" json:"required"`
+
+	prefixes []netip.Prefix
+}
+
+// Ports is a list of ports and inclusive port ranges.
+type Ports []PortRange
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	Start, End int
+}
+
+// UnmarshalYAML decodes a list of port numbers and "start-end" ranges.
+func (p *Ports) UnmarshalYAML(value *yaml.Node) error {
+	var items []string
+	if err := value.Decode(&items); err != nil {
+		return err
+	}
+
+	// An empty list is kept distinct from a missing one, so that
+	// "allowed: []" allows no open ports at all.
+	*p = Ports{}
+
+	for _, item := range items {
+		start, end, isRange := strings.Cut(item, "-")
+		if !isRange {
+			end = start
+		}
+		s, err1 := strconv.Atoi(strings.TrimSpace(start))
+		e, err2 := strconv.Atoi(strings.TrimSpace(end))
+		if err1 != nil || err2 != nil || s > e || s < 0 || e > 65535 {
+			return fmt.Errorf("invalid port or range: %s", item)
+		}
+		*p = append(*p, PortRange{Start: s, End: e})
+	}
+	return nil
+}
+
+// Contains reports whether port is in the list.
+func (p Ports) Contains(port int) bool {
+	return slices.ContainsFunc(p, func(r PortRange) bool {
+		return port >= r.Start && port <= r.End
+	})
+}
+
+// Load reads a policy from a YAML or JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse parses a YAML or JSON policy and resolves the hosts each rule
+// matches.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if len(rule.Match) == 0 {
+			return nil, fmt.Errorf("%s: match is empty", rule.Name)
+		}
+		prefixes, err := p.resolve(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+		rule.prefixes = prefixes
+	}
+	if p.Default != nil && p.Default.Name == "" {
+		p.Default.Name = "default"
+	}
+
+	return &p, nil
+}
+
+// resolve turns addresses, CIDR ranges and tag references into prefixes.
+func (p *Policy) resolve(match []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, m := range match {
+		if tag, ok := strings.CutPrefix(m, "tag:"); ok {
+			members, ok := p.Tags[tag]
+			if !ok {
+				return nil, fmt.Errorf("unknown tag: %s", tag)
+			}
+			for _, member := range members {
+				if strings.HasPrefix(member, "tag:") {
+					return nil, fmt.Errorf("tag %s: tags cannot refer to other tags", tag)
+				}
+			}
+			resolved, err := p.resolve(members)
+			if err != nil {
+				return nil, fmt.Errorf("tag %s: %w", tag, err)
+			}
+			prefixes = append(prefixes, resolved...)
+			continue
+		}
+
+		prefix, err := iputil.ParsePrefix(m)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// matches reports whether the rule applies to host.
+func (r *Rule) matches(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return slices.ContainsFunc(r.prefixes, func(p netip.Prefix) bool {
+		return p.Contains(addr)
+	})
+}
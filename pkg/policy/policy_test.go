@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(`
+tags:
+  web: [10.0.0.5, 10.0.1.0/24]
+rules:
+  - name: web servers
+    match: [tag:web]
+    allowed: [80, 443, "8000-8010"]
+    required: [443]
+  - match: [10.0.0.0/24]
+    allowed: []
+default:
+  allowed: [22]
+`))
+	require.NoError(t, err)
+
+	require.Len(t, p.Rules, 2)
+	assert.Equal(t, "web servers", p.Rules[0].Name)
+	assert.Equal(t, "rule 2", p.Rules[1].Name)
+	assert.Equal(t, "default", p.Default.Name)
+
+	assert.True(t, p.Rules[0].Allowed.Contains(8005))
+	assert.False(t, p.Rules[0].Allowed.Contains(8011))
+	assert.NotNil(t, p.Rules[1].Allowed, "an empty allowed list allows nothing")
+	assert.Nil(t, p.Rules[1].Required)
+
+	assert.True(t, p.Rules[0].matches("10.0.0.5"))
+	assert.True(t, p.Rules[0].matches("10.0.1.200"))
+	assert.False(t, p.Rules[0].matches("10.0.0.6"))
+}
+
+func TestParse_JSON(t *testing.T) {
+	p, err := Parse([]byte(`{"rules": [{"match": ["2001:db8::/64"], "allowed": [22]}]}`))
+	require.NoError(t, err)
+	assert.True(t, p.Rules[0].matches("2001:db8::1"))
+	assert.True(t, p.Rules[0].Allowed.Contains(22))
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{"unknown tag", `rules: [{match: [tag:db]}]`, "unknown tag: db"},
+		{"nested tag", "tags: {a: [tag:b], b: [10.0.0.1]}\nrules: [{match: [tag:a]}]", "tags cannot refer to other tags"},
+		{"bad CIDR", `rules: [{match: [10.0.0.0/33]}]`, "invalid CIDR"},
+		{"bad address", `rules: [{match: [example.com]}]`, "invalid address"},
+		{"empty match", `rules: [{allowed: [22]}]`, "match is empty"},
+		{"bad port", `rules: [{match: [10.0.0.1], allowed: [http]}]`, "invalid port or range: http"},
+		{"reversed range", `rules: [{match: [10.0.0.1], allowed: ["90-80"]}]`, "invalid port or range"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
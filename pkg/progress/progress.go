@@ -0,0 +1,166 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+const (
+	// barWidth is the number of characters inside the progress bar.
+	barWidth = 30
+	// redrawInterval is how often the interactive bar is redrawn.
+	redrawInterval = 200 * time.Millisecond
+	// DefaultInterval is how often a plain status line is printed when the
+	// output is not a terminal.
+	DefaultInterval = 10 * time.Second
+)
+
+// Source provides progress snapshots, usually a scanner.Progress.
+type Source interface {
+	Snapshot() scanner.ProgressSnapshot
+}
+
+// Reporter periodically writes the progress of a scan. Interactive reporters
+// redraw a progress bar in place; others print a plain status line at a
+// fixed interval so they are readable in logs.
+type Reporter struct {
+	writer      io.Writer
+	source      Source
+	interactive bool
+	interval    time.Duration
+	printed     bool
+	drawn       bool
+	mu          sync.Mutex
+	stop        chan struct{}
+	done        sync.WaitGroup
+}
+
+// NewReporter creates a new Reporter.
+func NewReporter(writer io.Writer, source Source) *Reporter {
+	return &Reporter{
+		writer:   writer,
+		source:   source,
+		interval: DefaultInterval,
+	}
+}
+
+// SetInteractive redraws a progress bar in place. It should only be enabled
+// when writing to a terminal.
+func (r *Reporter) SetInteractive(interactive bool) {
+	r.interactive = interactive
+}
+
+// SetInterval sets how often a plain status line is printed.
+func (r *Reporter) SetInterval(interval time.Duration) {
+	r.interval = interval
+}
+
+// Start begins reporting in the background.
+func (r *Reporter) Start() {
+	interval := r.interval
+	if r.interactive {
+		interval = redrawInterval
+	}
+
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reporting. An interactive bar is erased; otherwise a final
+// status line is printed if any were printed during the scan.
+func (r *Reporter) Stop() {
+	close(r.stop)
+	r.done.Wait()
+
+	if r.interactive {
+		r.mu.Lock()
+		r.erase()
+		r.mu.Unlock()
+	} else if r.printed {
+		r.report()
+	}
+}
+
+// Wrap returns a writer that erases the progress bar before each write to
+// w, so output sharing the terminal with the bar is not garbled. The bar is
+// redrawn on the next tick.
+func (r *Reporter) Wrap(w io.Writer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.erase()
+		return w.Write(p)
+	})
+}
+
+// report writes the current progress once.
+func (r *Reporter) report() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.source.Snapshot()
+	r.printed = true
+	if r.interactive {
+		fmt.Fprint(r.writer, "\r\033[K"+Bar(s))
+		r.drawn = true
+	} else {
+		fmt.Fprintln(r.writer, Line(s))
+	}
+}
+
+// erase clears the progress bar if it is drawn. The caller must hold mu.
+func (r *Reporter) erase() {
+	if r.drawn {
+		fmt.Fprint(r.writer, "\r\033[K")
+		r.drawn = false
+	}
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// Bar renders a snapshot as a single line progress bar.
+func Bar(s scanner.ProgressSnapshot) string {
+	filled := int(s.Percent() / 100 * barWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	return fmt.Sprintf("[%s] %3.0f%% %s", bar, s.Percent(), details(s))
+}
+
+// Line renders a snapshot as a plain status line.
+func Line(s scanner.ProgressSnapshot) string {
+	return fmt.Sprintf("progress: %.1f%% %s", s.Percent(), details(s))
+}
+
+// details describes the counts, rate and ETA of a snapshot.
+func details(s scanner.ProgressSnapshot) string {
+	eta := "--"
+	if s.Completed > 0 {
+		eta = s.ETA().Round(time.Second).String()
+	}
+	return fmt.Sprintf("%d/%d probes, %.0f/s, %d open, ETA %s", s.Completed, s.Total, s.Rate(), s.Open, eta)
+}
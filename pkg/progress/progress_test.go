@@ -0,0 +1,108 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// fixedSource always returns the same snapshot.
+type fixedSource scanner.ProgressSnapshot
+
+func (f fixedSource) Snapshot() scanner.ProgressSnapshot {
+	return scanner.ProgressSnapshot(f)
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var halfway = scanner.ProgressSnapshot{Total: 200, Completed: 100, Open: 4, Elapsed: 10 * time.Second}
+
+func TestBar(t *testing.T) {
+	expected := "[===============>              ]  50% 100/200 probes, 10/s, 4 open, ETA 10s"
+	assert.Equal(t, expected, Bar(halfway))
+
+	done := scanner.ProgressSnapshot{Total: 10, Completed: 10, Elapsed: time.Second}
+	assert.True(t, strings.HasPrefix(Bar(done), "["+strings.Repeat("=", barWidth)+"] 100%"))
+}
+
+func TestLine(t *testing.T) {
+	assert.Equal(t, "progress: 50.0% 100/200 probes, 10/s, 4 open, ETA 10s", Line(halfway))
+	assert.Equal(t, "progress: 0.0% 0/200 probes, 0/s, 0 open, ETA --", Line(scanner.ProgressSnapshot{Total: 200}))
+}
+
+func TestReporter_Plain(t *testing.T) {
+	writer := &syncBuffer{}
+	r := NewReporter(writer, fixedSource(halfway))
+	r.SetInterval(10 * time.Millisecond)
+
+	r.Start()
+	time.Sleep(35 * time.Millisecond)
+	r.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	assert.GreaterOrEqual(t, len(lines), 2)
+	for _, line := range lines {
+		assert.Equal(t, Line(halfway), line)
+	}
+}
+
+func TestReporter_PlainQuietForShortScans(t *testing.T) {
+	writer := &syncBuffer{}
+	r := NewReporter(writer, fixedSource(halfway))
+
+	r.Start()
+	r.Stop()
+
+	assert.Empty(t, writer.String())
+}
+
+func TestReporter_Interactive(t *testing.T) {
+	writer := &syncBuffer{}
+	r := NewReporter(writer, fixedSource(halfway))
+	r.SetInteractive(true)
+
+	r.Start()
+	time.Sleep(redrawInterval + 50*time.Millisecond)
+	r.Stop()
+
+	out := writer.String()
+	assert.True(t, strings.HasPrefix(out, "\r\033[K"+Bar(halfway)))
+	assert.True(t, strings.HasSuffix(out, "\r\033[K"))
+	assert.NotContains(t, out, "\n")
+}
+
+func TestReporter_Wrap(t *testing.T) {
+	terminal := &syncBuffer{}
+	r := NewReporter(terminal, fixedSource(halfway))
+	r.SetInteractive(true)
+	stdout := r.Wrap(terminal)
+
+	r.report()
+	stdout.Write([]byte("row 1\n"))
+	stdout.Write([]byte("row 2\n"))
+
+	expected := "\r\033[K" + Bar(halfway) + "\r\033[K" + "row 1\n" + "row 2\n"
+	assert.Equal(t, expected, terminal.String())
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/checkpoint"
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// DefaultPorts are the ports scanned when Options.Ports is empty.
+const DefaultPorts = "1-1024"
+
+// DefaultTimeout is the connect timeout used when Options.Timeout is zero.
+const DefaultTimeout = 3 * time.Second
+
+// Options configures a scan.
+type Options struct {
+	// Targets are the CIDR ranges to scan.
+	Targets []string
+	// Ports are the ports to scan on every host, in any form accepted by
+	// iputil.ParsePorts. DefaultPorts is used if it is empty.
+	Ports string
+
+	// Timeout is how long to wait for each connection.
+	Timeout time.Duration
+	// BannerTimeout is how long to wait for a banner from each open port.
+	// Banners are not read when it is zero.
+	BannerTimeout time.Duration
+	// Concurrency limits the number of ports scanned at once. Every port is
+	// scanned at once when it is zero.
+	Concurrency int
+	// Rate limits the number of ports started each second. There is no
+	// limit when it is zero.
+	Rate float64
+	// Retries is how many more times a port that timed out is scanned.
+	Retries int
+
+	// Scanner probes each port. If it is nil, a scanner.PortScanner is
+	// used with the timeouts and metrics above.
+	Scanner scanner.Scanner
+	// Metrics, if set, is updated as the scan runs.
+	Metrics *scanner.Metrics
+	// Resume continues the scan recorded in a checkpoint. Ports it has
+	// results for are not scanned again, and its results are sent before
+	// the new ones.
+	Resume *checkpoint.State
+	// Command is the command line recorded in the ScanRun.
+	Command string
+	// MaxProbes, if greater than zero, limits the size of the scan,
+	// counting each port on each host. New rejects larger scans before
+	// listing their hosts.
+	MaxProbes int
+}
+
+// Scan is a scan ready to run. It can only be run once.
+type Scan struct {
+	opts   Options
+	hosts  []string
+	ports  []int
+	worker *scanner.Worker
+}
+
+// New prepares a scan, resolving its targets to hosts and parsing its
+// ports.
+func New(opts Options) (*Scan, error) {
+	if opts.Ports == "" {
+		opts.Ports = DefaultPorts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	ports, err := iputil.ParsePorts(opts.Ports)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ports %s: %w", opts.Ports, err)
+	}
+
+	probes := 0
+	for _, target := range opts.Targets {
+		count, err := iputil.CountIPs(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %s: %w", target, err)
+		}
+		if opts.MaxProbes > 0 && len(ports) > 0 && count > (opts.MaxProbes-probes)/len(ports) {
+			return nil, fmt.Errorf("scan probes more than %d ports", opts.MaxProbes)
+		}
+		probes += count * len(ports)
+	}
+
+	var hosts []string
+	for _, target := range opts.Targets {
+		ips, err := iputil.GetIPs(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %s: %w", target, err)
+		}
+		hosts = append(hosts, ips...)
+	}
+
+	if opts.Scanner == nil {
+		opts.Scanner = &scanner.PortScanner{
+			Timeout:       opts.Timeout,
+			BannerTimeout: opts.BannerTimeout,
+			Metrics:       opts.Metrics,
+		}
+	}
+	worker := scanner.NewWorker(opts.Scanner, checkpoint.Targets(hosts, ports, opts.Resume))
+	worker.SetConcurrency(opts.Concurrency)
+	worker.SetRate(opts.Rate)
+	worker.SetRetries(opts.Retries)
+	worker.SetMetrics(opts.Metrics)
+
+	return &Scan{opts: opts, hosts: hosts, ports: ports, worker: worker}, nil
+}
+
+// Hosts returns the hosts to scan, in order.
+func (s *Scan) Hosts() []string {
+	return s.hosts
+}
+
+// Ports returns the ports to scan on each host, in order.
+func (s *Scan) Ports() []int {
+	return s.ports
+}
+
+// Progress returns the progress of the scan. When resuming, it only
+// counts the ports still to scan.
+func (s *Scan) Progress() *scanner.Progress {
+	return s.worker.Progress()
+}
+
+// Results starts the scan and returns a channel of results, which is closed
+// when the scan finishes. Once ctx is done no more ports are started.
+func (s *Scan) Results(ctx context.Context) <-chan scanner.Port {
+	results := s.worker.RunContext(ctx)
+	if s.opts.Resume == nil {
+		return results
+	}
+
+	ch := make(chan scanner.Port)
+	go func() {
+		defer close(ch)
+		for _, p := range s.opts.Resume.Results {
+			ch <- p
+		}
+		for p := range results {
+			ch <- p
+		}
+	}()
+	return ch
+}
+
+// Stream runs the scan, writing every result to writer, and returns the
+// finished ScanRun with its summary.
+func (s *Scan) Stream(ctx context.Context, writer output.OutputWriter) (*output.ScanRun, error) {
+	run := &output.ScanRun{
+		Command:   s.opts.Command,
+		Targets:   s.opts.Targets,
+		Ports:     s.opts.Ports,
+		PortCount: len(s.ports),
+		Timeout:   s.opts.Timeout,
+		HostCount: len(s.hosts),
+		Start:     time.Now(),
+	}
+	// Stop starting probes if writing fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	err := output.Stream(writer, run, s.Results(ctx), nil)
+	return run, err
+}
+
+// Collect runs the scan and returns every result. The error is ctx's error
+// if the scan was interrupted.
+func (s *Scan) Collect(ctx context.Context) ([]output.Result, error) {
+	var results []output.Result
+	for p := range s.Results(ctx) {
+		results = append(results, output.NewResult(p))
+	}
+	return results, ctx.Err()
+}
@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// fakeScanner reports port 22 as open and every other port as closed.
+type fakeScanner struct{}
+
+func (fakeScanner) Scan(p scanner.Port) scanner.Port {
+	p.Status = scanner.Closed
+	if p.Port == 22 {
+		p.Status = scanner.Open
+	}
+	return p
+}
+
+func TestNew(t *testing.T) {
+	scan, err := New(Options{Targets: []string{"10.0.0.0/30", "10.0.1.0/30"}, Ports: "22,80"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.1.1", "10.0.1.2"}, scan.Hosts())
+	assert.Equal(t, []int{22, 80}, scan.Ports())
+	assert.Equal(t, 8, scan.Progress().Snapshot().Total)
+
+	scan, err = New(Options{Targets: []string{"10.0.0.0/30"}})
+	require.NoError(t, err)
+	assert.Len(t, scan.Ports(), 1024)
+	assert.Equal(t, DefaultTimeout, scan.opts.Timeout)
+
+	_, err = New(Options{Targets: []string{"10.0.0.0"}})
+	assert.ErrorContains(t, err, "invalid target 10.0.0.0")
+
+	_, err = New(Options{Targets: []string{"10.0.0.0/30"}, Ports: "http"})
+	assert.ErrorContains(t, err, "invalid ports http")
+}
+
+func TestNew_MaxProbes(t *testing.T) {
+	scan, err := New(Options{Targets: []string{"10.0.0.0/30", "10.0.1.0/30"}, Ports: "1-25", MaxProbes: 100})
+	require.NoError(t, err)
+	assert.Len(t, scan.Hosts(), 4)
+
+	_, err = New(Options{Targets: []string{"10.0.0.0/30", "10.0.1.0/30"}, Ports: "1-26", MaxProbes: 100})
+	assert.EqualError(t, err, "scan probes more than 100 ports")
+
+	// Huge ranges are rejected without listing their hosts.
+	_, err = New(Options{Targets: []string{"2001:db8::/64"}, Ports: "22", MaxProbes: 1 << 20})
+	assert.EqualError(t, err, "scan probes more than 1048576 ports")
+}
+
+func TestScan_Stream(t *testing.T) {
+	scan, err := New(Options{
+		Targets:     []string{"10.0.0.0/30"},
+		Ports:       "22,80",
+		Scanner:     fakeScanner{},
+		Concurrency: 2,
+		Command:     "network-scanner 10.0.0.0/30 22,80",
+	})
+	require.NoError(t, err)
+
+	var results []output.Result
+	run, err := scan.Stream(context.Background(), collector{results: &results})
+	require.NoError(t, err)
+
+	assert.Len(t, results, 4)
+	assert.Equal(t, []string{"10.0.0.0/30"}, run.Targets)
+	assert.Equal(t, "22,80", run.Ports)
+	assert.Equal(t, 2, run.PortCount)
+	assert.Equal(t, 2, run.HostCount)
+	assert.Equal(t, "network-scanner 10.0.0.0/30 22,80", run.Command)
+	require.NotNil(t, run.Summary)
+	assert.Equal(t, 2, run.Summary.Open)
+	assert.Equal(t, 2, run.Summary.Closed)
+}
+
+func TestScan_Collect_Cancelled(t *testing.T) {
+	scan, err := New(Options{Targets: []string{"10.0.0.0/24"}, Ports: "22", Scanner: fakeScanner{}, Concurrency: 1})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := scan.Collect(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, len(results), 254)
+}
+
+// collector is an OutputWriter that keeps every result.
+type collector struct {
+	results *[]output.Result
+}
+
+func (c collector) Begin(run *output.ScanRun) error { return nil }
+
+func (c collector) WriteResult(r output.Result) error {
+	*c.results = append(*c.results, r)
+	return nil
+}
+
+func (c collector) End(run *output.ScanRun) error { return nil }
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus metrics a Worker and PortScanner report.
+type Metrics struct {
+	probes       *prometheus.CounterVec
+	dialErrors   *prometheus.CounterVec
+	inFlight     prometheus.Gauge
+	scanDuration prometheus.Histogram
+	openPorts    *prometheus.GaugeVec
+}
+
+// NewMetrics creates the scanner's metrics and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		probes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "netscan_probes_total",
+			Help: "Probes sent, including retries, by result.",
+		}, []string{"status"}),
+		dialErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "netscan_dial_errors_total",
+			Help: "Failed connection attempts, by class of error: refused, timeout, unreachable or other.",
+		}, []string{"class"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "netscan_probes_in_flight",
+			Help: "Ports being probed right now.",
+		}),
+		scanDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "netscan_scan_duration_seconds",
+			Help:    "How long scans took.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 10),
+		}),
+		openPorts: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "netscan_open_ports",
+			Help: "Open ports found on each host by its latest completed scan. Hosts without open ports are not listed.",
+		}, []string{"host"}),
+	}
+
+	// Report every status and class from the start, so that rates work
+	// before the first of each is seen.
+	for _, status := range []Status{Open, Closed, Timeout} {
+		m.probes.WithLabelValues(statusLabel(status))
+	}
+	for _, class := range []string{"refused", "timeout", "unreachable", "other"} {
+		m.dialErrors.WithLabelValues(class)
+	}
+
+	reg.MustRegister(m.probes, m.dialErrors, m.inFlight, m.scanDuration, m.openPorts)
+	return m
+}
+
+// statusLabel returns the label value for a status.
+func statusLabel(s Status) string {
+	text, err := s.MarshalText()
+	if err != nil {
+		return "unknown"
+	}
+	return string(text)
+}
+
+// dialErrorClass classifies a connection error for the dial errors metric.
+func dialErrorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "refused"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return "unreachable"
+	default:
+		return "other"
+	}
+}
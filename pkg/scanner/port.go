@@ -1,6 +1,9 @@
 package scanner
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Status represents the status of a port.
 type Status int
@@ -24,11 +27,46 @@ func (s Status) String() string {
 	}
 }
 
+// MarshalText encodes the status as a short lowercase keyword.
+func (s Status) MarshalText() ([]byte, error) {
+	switch s {
+	case Open:
+		return []byte("open"), nil
+	case Closed:
+		return []byte("closed"), nil
+	case Timeout:
+		return []byte("timeout"), nil
+	default:
+		return nil, fmt.Errorf("unknown status: %d", int(s))
+	}
+}
+
+// UnmarshalText decodes a status from its keyword or display form.
+func (s *Status) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "open", "Open":
+		*s = Open
+	case "closed", "Closed":
+		*s = Closed
+	case "timeout", "Timed Out":
+		*s = Timeout
+	default:
+		return fmt.Errorf("unknown status: %s", text)
+	}
+	return nil
+}
+
 // Port represents a single port on a single host.
 type Port struct {
-	Host   string
-	Port   int
-	Status Status
+	Host    string
+	Port    int
+	Status  Status
+	Latency time.Duration
+	Banner  string
+	// Unreachable is set on a closed port whose host or network could not
+	// be reached. Unlike a refused connection, it doesn't show that there
+	// is a host at the address.
+	Unreachable bool
 }
 
 func (p Port) String() string {
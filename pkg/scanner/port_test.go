@@ -51,3 +51,24 @@ func TestPort_String(t *testing.T) {
 		t.Errorf("Port.String() = %v, want %v", got, want)
 	}
 }
+
+func TestStatus_MarshalText(t *testing.T) {
+	for _, s := range []Status{Open, Closed, Timeout} {
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v) returned error: %v", s, err)
+		}
+
+		var got Status
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) returned error: %v", text, err)
+		}
+		if got != s {
+			t.Errorf("round trip of %v gave %v", s, got)
+		}
+	}
+
+	if _, err := Status(99).MarshalText(); err == nil {
+		t.Error("expected error for unknown status")
+	}
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Progress tracks how far through its ports a Worker is. It is safe to read
+// from other goroutines while the Worker is running.
+type Progress struct {
+	total     int64
+	completed atomic.Int64
+	open      atomic.Int64
+	start     atomic.Int64
+}
+
+// ProgressSnapshot is a point-in-time view of a Progress.
+type ProgressSnapshot struct {
+	Total     int
+	Completed int
+	Open      int
+	Elapsed   time.Duration
+}
+
+// Rate returns the number of probes completed per second.
+func (s ProgressSnapshot) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Completed) / s.Elapsed.Seconds()
+}
+
+// ETA estimates the time remaining from the rate so far. It is zero until
+// the first probe completes.
+func (s ProgressSnapshot) ETA() time.Duration {
+	rate := s.Rate()
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(float64(s.Total-s.Completed) / rate * float64(time.Second))
+}
+
+// Percent returns the percentage of probes completed.
+func (s ProgressSnapshot) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return float64(s.Completed) / float64(s.Total) * 100
+}
+
+// Snapshot returns the current progress.
+func (p *Progress) Snapshot() ProgressSnapshot {
+	s := ProgressSnapshot{
+		Total:     int(p.total),
+		Completed: int(p.completed.Load()),
+		Open:      int(p.open.Load()),
+	}
+	if start := p.start.Load(); start != 0 {
+		s.Elapsed = time.Since(time.Unix(0, start))
+	}
+	return s
+}
+
+// begin records the start of a scan.
+func (p *Progress) begin() {
+	p.start.Store(time.Now().UnixNano())
+}
+
+// record counts a completed probe.
+func (p *Progress) record(port Port) {
+	p.completed.Add(1)
+	if port.Status == Open {
+		p.open.Add(1)
+	}
+}
@@ -0,0 +1,63 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressSnapshot(t *testing.T) {
+	s := ProgressSnapshot{Total: 100, Completed: 25, Open: 3, Elapsed: 5 * time.Second}
+
+	if got := s.Rate(); got != 5 {
+		t.Errorf("Rate() = %v, want 5", got)
+	}
+	if got := s.ETA(); got != 15*time.Second {
+		t.Errorf("ETA() = %v, want 15s", got)
+	}
+	if got := s.Percent(); got != 25 {
+		t.Errorf("Percent() = %v, want 25", got)
+	}
+}
+
+func TestProgressSnapshot_NotStarted(t *testing.T) {
+	s := ProgressSnapshot{Total: 100}
+
+	if got := s.Rate(); got != 0 {
+		t.Errorf("Rate() = %v, want 0", got)
+	}
+	if got := s.ETA(); got != 0 {
+		t.Errorf("ETA() = %v, want 0", got)
+	}
+}
+
+func TestWorker_Progress(t *testing.T) {
+	ports := []Port{{Port: 80}, {Port: 443}, {Port: 8080}}
+	mockScanner := &MockScanner{
+		ScanFunc: func(p Port) Port {
+			p.Status = Closed
+			if p.Port == 443 {
+				p.Status = Open
+			}
+			return p
+		},
+	}
+
+	worker := NewWorker(mockScanner, ports)
+	if s := worker.Progress().Snapshot(); s.Total != 3 || s.Completed != 0 {
+		t.Errorf("expected 0 of 3 completed before running, got %d of %d", s.Completed, s.Total)
+	}
+
+	for range worker.Run() {
+	}
+
+	s := worker.Progress().Snapshot()
+	if s.Completed != 3 {
+		t.Errorf("expected 3 completed, got %d", s.Completed)
+	}
+	if s.Open != 1 {
+		t.Errorf("expected 1 open, got %d", s.Open)
+	}
+	if s.Elapsed <= 0 {
+		t.Errorf("expected elapsed time to be set, got %v", s.Elapsed)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"net"
 	"strings"
 	"time"
+	"unicode"
 )
 
+// maxBannerSize is the most bytes read from a port when grabbing a banner.
+const maxBannerSize = 256
+
 // Scanner is the interface for a port scanner.
 type Scanner interface {
 	Scan(p Port) Port
@@ -14,6 +18,11 @@ type Scanner interface {
 // PortScanner is a concrete implementation of Scanner.
 type PortScanner struct {
 	Timeout time.Duration
+	// BannerTimeout is how long to wait for an open port to send a banner.
+	// Banners are not read when it is zero.
+	BannerTimeout time.Duration
+	// Metrics, if set, counts dial errors by class.
+	Metrics *Metrics
 }
 
 // NewPortScanner creates a new PortScanner.
@@ -24,16 +33,46 @@ func NewPortScanner(timeout time.Duration) Scanner {
 // Scan performs the port scan.
 func (ps *PortScanner) Scan(p Port) Port {
 	address := p.String()
+	start := time.Now()
 	conn, err := net.DialTimeout("tcp", address, ps.Timeout)
+	p.Latency = time.Since(start)
 	if err != nil {
+		class := dialErrorClass(err)
+		if ps.Metrics != nil {
+			ps.Metrics.dialErrors.WithLabelValues(class).Inc()
+		}
 		if strings.Contains(err.Error(), "timeout") {
 			p.Status = Timeout
 		} else {
 			p.Status = Closed
+			p.Unreachable = class == "unreachable"
 		}
 		return p
 	}
 	defer conn.Close()
 	p.Status = Open
+
+	if ps.BannerTimeout > 0 {
+		p.Banner = readBanner(conn, ps.BannerTimeout)
+	}
 	return p
 }
+
+// readBanner reads whatever the remote end sends first, up to maxBannerSize
+// bytes, and returns it with control characters stripped.
+func readBanner(conn net.Conn, timeout time.Duration) string {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return ""
+	}
+
+	buf := make([]byte, maxBannerSize)
+	n, _ := conn.Read(buf)
+
+	banner := strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return ' '
+	}, string(buf[:n]))
+	return strings.TrimSpace(banner)
+}
@@ -1,9 +1,15 @@
 package scanner
 
 import (
+	"errors"
 	"net"
+	"os"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestPortScanner_Open(t *testing.T) {
@@ -53,3 +59,77 @@ func TestPortScanner_Timeout(t *testing.T) {
 		t.Errorf("expected status Timeout, got %v", result.Status)
 	}
 }
+
+func TestPortScanner_Banner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	}()
+
+	p := Port{
+		Host: "127.0.0.1",
+		Port: listener.Addr().(*net.TCPAddr).Port,
+	}
+
+	scanner := &PortScanner{Timeout: 3 * time.Second, BannerTimeout: time.Second}
+	result := scanner.Scan(p)
+
+	if result.Status != Open {
+		t.Errorf("expected status Open, got %v", result.Status)
+	}
+	if result.Banner != "SSH-2.0-OpenSSH_9.6" {
+		t.Errorf("expected banner %q, got %q", "SSH-2.0-OpenSSH_9.6", result.Banner)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("expected a positive latency, got %v", result.Latency)
+	}
+}
+
+func TestPortScanner_Metrics(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	scanner := &PortScanner{Timeout: time.Second, Metrics: metrics}
+	scanner.Scan(Port{Host: "127.0.0.1", Port: port})
+
+	if refused := testutil.ToFloat64(metrics.dialErrors.WithLabelValues("refused")); refused != 1 {
+		t.Errorf("expected 1 refused dial error, got %v", refused)
+	}
+}
+
+func TestDialErrorClass(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, "refused"},
+		{"timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, "timeout"},
+		{"host unreachable", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)}, "unreachable"},
+		{"network unreachable", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ENETUNREACH)}, "unreachable"},
+		{"other", errors.New("no such host"), "other"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if class := dialErrorClass(tc.err); class != tc.expected {
+				t.Errorf("dialErrorClass() = %v, want %v", class, tc.expected)
+			}
+		})
+	}
+}
@@ -1,38 +1,212 @@
 package scanner
 
-import "sync"
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateWindow is how long each probe of a scan limited only by its rate is
+// allowed to take before the rate can no longer be kept up.
+const rateWindow = 10 * time.Second
 
 // Worker manages the concurrent scanning of ports.
 type Worker struct {
-	scanner Scanner
-	ports   []Port
+	scanner     Scanner
+	ports       []Port
+	progress    *Progress
+	concurrency int
+	rate        float64
+	retries     int
+	metrics     *Metrics
 }
 
 // NewWorker creates a new Worker.
 func NewWorker(scanner Scanner, ports []Port) *Worker {
 	return &Worker{
-		scanner: scanner,
-		ports:   ports,
+		scanner:  scanner,
+		ports:    ports,
+		progress: &Progress{total: int64(len(ports))},
 	}
 }
 
+// SetConcurrency limits the number of ports scanned at once. Ports are then
+// started in the order they were given. When n is zero or less, which is
+// the default, every port is scanned at once.
+func (w *Worker) SetConcurrency(n int) {
+	w.concurrency = n
+}
+
+// SetRate limits how many ports are started each second. Ports are then
+// started in the order they were given. Unless the concurrency is also
+// limited, enough ports are scanned at once to keep up with the rate while
+// each takes up to rateWindow. When rate is zero or less, which is the
+// default, there is no limit.
+func (w *Worker) SetRate(rate float64) {
+	w.rate = rate
+}
+
+// SetRetries sets how many more times a port that timed out is scanned
+// before it is reported as timed out. Retries do not count towards the
+// rate limit.
+func (w *Worker) SetRetries(n int) {
+	w.retries = n
+}
+
+// SetMetrics reports the scan's probes, duration and open ports to m.
+func (w *Worker) SetMetrics(m *Metrics) {
+	w.metrics = m
+}
+
+// workers returns the number of ports scanned at once when the concurrency
+// or rate is limited.
+func (w *Worker) workers() int {
+	if w.concurrency > 0 {
+		return min(w.concurrency, len(w.ports))
+	}
+	return int(min(math.Ceil(w.rate*rateWindow.Seconds()), float64(len(w.ports))))
+}
+
+// Progress returns the progress of the scan.
+func (w *Worker) Progress() *Progress {
+	return w.progress
+}
+
 // Run starts the concurrent scanning and returns a channel of results.
 func (w *Worker) Run() <-chan Port {
+	return w.RunContext(context.Background())
+}
+
+// RunContext is like Run, but stops starting new scans once ctx is done.
+// Scans already in progress finish and their results are still sent before
+// the channel is closed.
+func (w *Worker) RunContext(ctx context.Context) <-chan Port {
 	resultsChan := make(chan Port)
 	var wg sync.WaitGroup
+	start := time.Now()
+	var openPorts *openPortCounter
+	if w.metrics != nil {
+		openPorts = &openPortCounter{counts: make(map[string]int)}
+	}
+
+	// probe scans a port once. Every probe is counted, retries included.
+	probe := func(port Port) Port {
+		if w.metrics == nil {
+			return w.scanner.Scan(port)
+		}
+		w.metrics.inFlight.Inc()
+		result := w.scanner.Scan(port)
+		w.metrics.inFlight.Dec()
+		w.metrics.probes.WithLabelValues(statusLabel(result.Status)).Inc()
+		return result
+	}
+	scan := func(port Port) {
+		result := probe(port)
+		for i := 0; i < w.retries && result.Status == Timeout && ctx.Err() == nil; i++ {
+			result = probe(port)
+		}
+		if w.metrics != nil {
+			openPorts.add(result)
+		}
+		w.progress.record(result)
+		resultsChan <- result
+	}
+
+	w.progress.begin()
+	if w.concurrency <= 0 && w.rate <= 0 {
+		for _, p := range w.ports {
+			wg.Add(1)
+			go func(port Port) {
+				defer wg.Done()
+				if ctx.Err() == nil {
+					scan(port)
+				}
+			}(p)
+		}
+	} else {
+		jobs := make(chan Port)
+		for range w.workers() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for port := range jobs {
+					scan(port)
+				}
+			}()
+		}
 
-	for _, p := range w.ports {
-		wg.Add(1)
-		go func(port Port) {
-			defer wg.Done()
-			resultsChan <- w.scanner.Scan(port)
-		}(p)
+		go func() {
+			defer close(jobs)
+			var tick <-chan time.Time
+			if w.rate > 0 {
+				ticker := time.NewTicker(max(time.Duration(float64(time.Second)/w.rate), 1))
+				defer ticker.Stop()
+				tick = ticker.C
+			}
+
+			for i, p := range w.ports {
+				if ctx.Err() != nil {
+					return
+				}
+				if tick != nil && i > 0 {
+					select {
+					case <-tick:
+					case <-ctx.Done():
+						return
+					}
+				}
+				select {
+				case jobs <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
 	}
 
 	go func() {
 		wg.Wait()
+		if w.metrics != nil {
+			w.metrics.scanDuration.Observe(time.Since(start).Seconds())
+			// An interrupted scan would wrongly report the ports it did
+			// not reach as closed.
+			if ctx.Err() == nil {
+				openPorts.report(w.metrics)
+			}
+		}
 		close(resultsChan)
 	}()
 
 	return resultsChan
 }
+
+// openPortCounter counts the open ports on each host during a scan.
+type openPortCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *openPortCounter) add(p Port) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p.Status == Open {
+		c.counts[p.Host]++
+	} else if _, ok := c.counts[p.Host]; !ok {
+		c.counts[p.Host] = 0
+	}
+}
+
+// report sets the open ports metric for every host that was scanned,
+// removing hosts that no longer have open ports.
+func (c *openPortCounter) report(m *Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for host, count := range c.counts {
+		if count == 0 {
+			m.openPorts.DeleteLabelValues(host)
+		} else {
+			m.openPorts.WithLabelValues(host).Set(float64(count))
+		}
+	}
+}
@@ -1,8 +1,15 @@
 package scanner
 
 import (
+	"context"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 // MockScanner is a mock implementation of the Scanner interface.
@@ -98,3 +105,253 @@ func TestWorker_Run_Concurrent(t *testing.T) {
 		}
 	}
 }
+
+func TestWorker_SetConcurrency(t *testing.T) {
+	var ports []Port
+	for i := 1; i <= 50; i++ {
+		ports = append(ports, Port{Port: i})
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	mockScanner := &MockScanner{
+		ScanFunc: func(p Port) Port {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return p
+		},
+	}
+
+	worker := NewWorker(mockScanner, ports)
+	worker.SetConcurrency(3)
+
+	count := 0
+	for range worker.Run() {
+		count++
+	}
+
+	if count != len(ports) {
+		t.Errorf("expected %d results, got %d", len(ports), count)
+	}
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 concurrent scans, got %d", maxRunning)
+	}
+}
+
+func TestWorker_SetRate(t *testing.T) {
+	var ports []Port
+	for i := 1; i <= 5; i++ {
+		ports = append(ports, Port{Port: i})
+	}
+
+	var mu sync.Mutex
+	var started []time.Time
+	mockScanner := &MockScanner{
+		ScanFunc: func(p Port) Port {
+			mu.Lock()
+			started = append(started, time.Now())
+			mu.Unlock()
+			return p
+		},
+	}
+
+	worker := NewWorker(mockScanner, ports)
+	worker.SetRate(100)
+
+	count := 0
+	for range worker.Run() {
+		count++
+	}
+
+	if count != len(ports) {
+		t.Errorf("expected %d results, got %d", len(ports), count)
+	}
+	// Five ports at 100 a second take at least 40ms to start.
+	if elapsed := started[len(started)-1].Sub(started[0]); elapsed < 35*time.Millisecond {
+		t.Errorf("expected ports to be started over at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestWorker_Workers(t *testing.T) {
+	ports := make([]Port, 10000)
+
+	testCases := []struct {
+		name        string
+		concurrency int
+		rate        float64
+		expected    int
+	}{
+		{"concurrency", 50, 0, 50},
+		{"concurrency above ports", 20000, 0, 10000},
+		{"rate", 0, 100, 1000},
+		{"slow rate", 0, 0.05, 1},
+		{"rate above ports", 0, 1e6, 10000},
+		{"concurrency and rate", 5, 100, 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			worker := NewWorker(&MockScanner{}, ports)
+			worker.SetConcurrency(tc.concurrency)
+			worker.SetRate(tc.rate)
+			if got := worker.workers(); got != tc.expected {
+				t.Errorf("expected %d workers, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWorker_SetRetries(t *testing.T) {
+	ports := []Port{{Port: 80}, {Port: 443}}
+
+	var mu sync.Mutex
+	attempts := make(map[int]int)
+	mockScanner := &MockScanner{
+		ScanFunc: func(p Port) Port {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[p.Port]++
+			// Port 80 answers on its second attempt; 443 never does.
+			if p.Port == 80 && attempts[p.Port] == 2 {
+				p.Status = Open
+			} else {
+				p.Status = Timeout
+			}
+			return p
+		},
+	}
+
+	worker := NewWorker(mockScanner, ports)
+	worker.SetRetries(2)
+
+	results := make(map[int]Status)
+	for p := range worker.Run() {
+		results[p.Port] = p.Status
+	}
+
+	if results[80] != Open || attempts[80] != 2 {
+		t.Errorf("expected port 80 to be open after 2 attempts, got %s after %d", results[80], attempts[80])
+	}
+	if results[443] != Timeout || attempts[443] != 3 {
+		t.Errorf("expected port 443 to time out after 3 attempts, got %s after %d", results[443], attempts[443])
+	}
+}
+
+func TestWorker_RunContext_Cancel(t *testing.T) {
+	var ports []Port
+	for i := 1; i <= 100; i++ {
+		ports = append(ports, Port{Port: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockScanner := &MockScanner{
+		ScanFunc: func(p Port) Port {
+			if p.Port == 5 {
+				cancel()
+			}
+			return p
+		},
+	}
+
+	worker := NewWorker(mockScanner, ports)
+	worker.SetConcurrency(1)
+
+	var scanned []int
+	for p := range worker.RunContext(ctx) {
+		scanned = append(scanned, p.Port)
+	}
+
+	if len(scanned) < 5 || len(scanned) > 6 {
+		t.Errorf("expected the scan to stop after port 5, scanned %v", scanned)
+	}
+	for i, port := range scanned {
+		if port != i+1 {
+			t.Errorf("expected ports to be scanned in order, got %v", scanned)
+			break
+		}
+	}
+}
+
+func TestWorker_SetMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	metrics := NewMetrics(reg)
+
+	scan := func(ports []Port) {
+		worker := NewWorker(&MockScanner{
+			ScanFunc: func(p Port) Port {
+				p.Status = Closed
+				if p.Port == 22 {
+					p.Status = Open
+				}
+				return p
+			},
+		}, ports)
+		worker.SetMetrics(metrics)
+		for range worker.Run() {
+		}
+	}
+
+	scan([]Port{{Host: "10.0.0.1", Port: 22}, {Host: "10.0.0.1", Port: 80}, {Host: "10.0.0.2", Port: 22}})
+	scan([]Port{{Host: "10.0.0.2", Port: 80}})
+
+	expected := `
+# HELP netscan_open_ports Open ports found on each host by its latest completed scan. Hosts without open ports are not listed.
+# TYPE netscan_open_ports gauge
+netscan_open_ports{host="10.0.0.1"} 1
+# HELP netscan_probes_in_flight Ports being probed right now.
+# TYPE netscan_probes_in_flight gauge
+netscan_probes_in_flight 0
+# HELP netscan_probes_total Probes sent, including retries, by result.
+# TYPE netscan_probes_total counter
+netscan_probes_total{status="closed"} 2
+netscan_probes_total{status="open"} 2
+netscan_probes_total{status="timeout"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"netscan_open_ports", "netscan_probes_in_flight", "netscan_probes_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(metrics.scanDuration); count != 1 {
+		t.Errorf("expected a scan duration histogram, got %d metrics", count)
+	}
+}
+
+func TestWorker_SetMetrics_Retries(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	metrics := NewMetrics(reg)
+
+	attempts := 0
+	worker := NewWorker(&MockScanner{
+		ScanFunc: func(p Port) Port {
+			attempts++
+			p.Status = Timeout
+			if attempts == 3 {
+				p.Status = Open
+			}
+			return p
+		},
+	}, []Port{{Host: "10.0.0.1", Port: 22}})
+	worker.SetRetries(2)
+	worker.SetMetrics(metrics)
+	for range worker.Run() {
+	}
+
+	expected := `
+# HELP netscan_probes_total Probes sent, including retries, by result.
+# TYPE netscan_probes_total counter
+netscan_probes_total{status="closed"} 0
+netscan_probes_total{status="open"} 1
+netscan_probes_total{status="timeout"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "netscan_probes_total"); err != nil {
+		t.Error(err)
+	}
+}
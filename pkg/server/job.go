@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// JobStatus is the state of a scan job.
+type JobStatus string
+
+const (
+	Queued    JobStatus = "queued"
+	Running   JobStatus = "running"
+	Done      JobStatus = "done"
+	Cancelled JobStatus = "cancelled"
+)
+
+// finished reports whether a job in this state will not change again.
+func (s JobStatus) finished() bool {
+	return s == Done || s == Cancelled
+}
+
+// Duration is a time.Duration written in JSON as a string such as "500ms".
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration from a string such as "1.5s".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"500ms\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// JobRequest describes a scan to run.
+type JobRequest struct {
+	// Targets lists the CIDR ranges to scan.
+	Targets []string `json:"targets"`
+	// Ports is a list or range of ports, 1-1024 by default.
+	Ports string `json:"ports"`
+	// Timeout is the timeout for each port, 3s by default.
+	Timeout Duration `json:"timeout"`
+	// Concurrency is the most ports scanned at once.
+	Concurrency int `json:"concurrency"`
+	// Banner is how long to wait for a banner from open ports. Banners are
+	// not read if it is zero.
+	Banner Duration `json:"banner"`
+}
+
+// Job is a scan submitted to the server.
+type Job struct {
+	id      string
+	request JobRequest
+	scan    *runner.Scan
+	created time.Time
+	cancel  context.CancelFunc
+	ctx     context.Context
+
+	mu       sync.Mutex
+	status   JobStatus
+	started  time.Time
+	finished time.Time
+	progress *scanner.Progress
+	results  []output.Result
+	// changed is closed and replaced whenever a result is added or the
+	// status changes, to wake up streams.
+	changed chan struct{}
+}
+
+func newJob(id string, request JobRequest, scan *runner.Scan, parent context.Context) *Job {
+	ctx, cancel := context.WithCancel(parent)
+	return &Job{
+		id:      id,
+		request: request,
+		scan:    scan,
+		created: time.Now(),
+		ctx:     ctx,
+		cancel:  cancel,
+		status:  Queued,
+		changed: make(chan struct{}),
+	}
+}
+
+// broadcast wakes up everything waiting for the job to change. j.mu must be
+// held.
+func (j *Job) broadcast() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// start marks a queued job as running. It returns false if the job was
+// cancelled while it was queued.
+func (j *Job) start(progress *scanner.Progress) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != Queued {
+		return false
+	}
+	j.status = Running
+	j.started = time.Now()
+	j.progress = progress
+	j.broadcast()
+	return true
+}
+
+// add records a result.
+func (j *Job) add(r output.Result) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.results = append(j.results, r)
+	j.broadcast()
+}
+
+// finish marks the job as done, or cancelled if its context was cancelled.
+func (j *Job) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.finished() {
+		return
+	}
+	j.status = Done
+	if j.ctx.Err() != nil {
+		j.status = Cancelled
+	}
+	j.finished = time.Now()
+	j.cancel()
+	j.broadcast()
+}
+
+// stop cancels the job. A queued job is finished immediately; a running one
+// finishes once the scans in progress complete.
+func (j *Job) stop() {
+	j.cancel()
+	j.mu.Lock()
+	queued := j.status == Queued
+	j.mu.Unlock()
+	if queued {
+		j.finish()
+	}
+}
+
+// since returns the results after the first n, the job's status, and a
+// channel that is closed when either changes.
+func (j *Job) since(n int) ([]output.Result, JobStatus, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var results []output.Result
+	if n < len(j.results) {
+		results = j.results[n:len(j.results):len(j.results)]
+	}
+	return results, j.status, j.changed
+}
+
+// jobProgress is the progress of a job in the API.
+type jobProgress struct {
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Open      int     `json:"open"`
+	Percent   float64 `json:"percent"`
+	Rate      float64 `json:"rate"`
+	EtaMs     int64   `json:"eta_ms"`
+}
+
+// jobView is a job as returned by the API.
+type jobView struct {
+	ID       string      `json:"id"`
+	Status   JobStatus   `json:"status"`
+	Request  JobRequest  `json:"request"`
+	Created  time.Time   `json:"created"`
+	Started  *time.Time  `json:"started,omitempty"`
+	Finished *time.Time  `json:"finished,omitempty"`
+	Progress jobProgress `json:"progress"`
+}
+
+// view returns a snapshot of the job for the API.
+func (j *Job) view() jobView {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	v := jobView{
+		ID:       j.id,
+		Status:   j.status,
+		Request:  j.request,
+		Created:  j.created,
+		Progress: jobProgress{Total: j.scan.Progress().Snapshot().Total},
+	}
+	if !j.started.IsZero() {
+		started := j.started
+		v.Started = &started
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		v.Finished = &finished
+	}
+	if j.progress != nil {
+		s := j.progress.Snapshot()
+		if !j.finished.IsZero() {
+			s.Elapsed = j.finished.Sub(j.started)
+		}
+		v.Progress = jobProgress{
+			Total:     s.Total,
+			Completed: s.Completed,
+			Open:      s.Open,
+			Percent:   s.Percent(),
+			Rate:      s.Rate(),
+			EtaMs:     s.ETA().Milliseconds(),
+		}
+	}
+	return v
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+const (
+	// DefaultMaxRunning is the default number of jobs that run at once.
+	DefaultMaxRunning = 2
+	// DefaultMaxQueued is the default number of jobs that can wait to run.
+	DefaultMaxQueued = 16
+	// DefaultMaxProbes is the default limit on the ports a job may scan,
+	// counting each port on each host.
+	DefaultMaxProbes = 1 << 20
+	// DefaultMaxConcurrency is the default limit on the ports a job may
+	// scan at once.
+	DefaultMaxConcurrency = 2000
+	// defaultConcurrency is the concurrency of jobs that do not set one.
+	defaultConcurrency = 500
+	// maxFinishedJobs is how many finished jobs are kept. Older ones are
+	// forgotten.
+	maxFinishedJobs = 100
+	// keepAliveInterval is how often an idle event stream sends a comment,
+	// so proxies do not close it.
+	keepAliveInterval = 15 * time.Second
+)
+
+// Server is an HTTP API for running scans. Jobs are queued and run a few at
+// a time; submitting a job when the queue is full fails.
+type Server struct {
+	mux       *http.ServeMux
+	queue     chan *Job
+	maxProbes int
+	// maxConcurrency is the most ports a job may scan at once.
+	maxConcurrency int
+	// newScanner, if set, replaces the scanner jobs use. It is set by
+	// tests.
+	newScanner func(JobRequest) scanner.Scanner
+	metrics    *scanner.Metrics
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	// order lists job IDs in the order they were submitted.
+	order []string
+}
+
+// New creates a new Server that runs up to maxRunning jobs at once, with up
+// to maxQueued more waiting. maxRunning must be at least one and maxQueued
+// must not be negative.
+func New(maxRunning, maxQueued int) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		mux:            http.NewServeMux(),
+		queue:          make(chan *Job, maxQueued),
+		maxProbes:      DefaultMaxProbes,
+		maxConcurrency: DefaultMaxConcurrency,
+		ctx:            ctx,
+		cancel:         cancel,
+		jobs:           make(map[string]*Job),
+	}
+
+	s.mux.HandleFunc("POST /jobs", s.handleSubmit)
+	s.mux.HandleFunc("GET /jobs", s.handleList)
+	s.mux.HandleFunc("GET /jobs/{id}", s.handleGet)
+	s.mux.HandleFunc("GET /jobs/{id}/results", s.handleResults)
+	s.mux.HandleFunc("GET /jobs/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("DELETE /jobs/{id}", s.handleCancel)
+
+	for range maxRunning {
+		s.wg.Add(1)
+		go s.runJobs()
+	}
+	return s
+}
+
+// SetMaxProbes limits the number of ports a job may scan, counting each port
+// on each host.
+func (s *Server) SetMaxProbes(n int) {
+	s.maxProbes = n
+}
+
+// SetMaxConcurrency limits the number of ports a job may scan at once. Jobs
+// that ask for more are rejected, and jobs that don't ask use at most n.
+func (s *Server) SetMaxConcurrency(n int) {
+	s.maxConcurrency = n
+}
+
+// SetMetrics reports the probes, duration and open ports of every job to m.
+func (s *Server) SetMetrics(m *scanner.Metrics) {
+	s.metrics = m
+}
+
+// ServeHTTP serves the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels every job and waits for running jobs to stop. Event streams
+// end once their job has stopped.
+func (s *Server) Close() {
+	s.cancel()
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		job.stop()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// runJobs runs queued jobs until the server is closed.
+func (s *Server) runJobs() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case job := <-s.queue:
+			s.run(job)
+		}
+	}
+}
+
+// run runs a single job.
+func (s *Server) run(job *Job) {
+	if !job.start(job.scan.Progress()) {
+		return
+	}
+
+	for p := range job.scan.Results(job.ctx) {
+		job.add(output.NewResult(p))
+	}
+	job.finish()
+	s.forgetOldJobs()
+}
+
+// forgetOldJobs removes the oldest finished jobs beyond maxFinishedJobs.
+func (s *Server) forgetOldJobs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := 0
+	for _, id := range s.order {
+		if s.jobs[id].view().Status.finished() {
+			finished++
+		}
+	}
+
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if finished > maxFinishedJobs && s.jobs[id].view().Status.finished() {
+			delete(s.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// parseRequest validates a job request, fills in defaults and prepares its
+// scan. Jobs larger than the probe limit are rejected before their targets
+// are listed.
+func (s *Server) parseRequest(req *JobRequest) (*runner.Scan, error) {
+	if len(req.Targets) == 0 {
+		return nil, errors.New("targets is empty")
+	}
+	if req.Ports == "" {
+		req.Ports = runner.DefaultPorts
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = Duration(runner.DefaultTimeout)
+	}
+	if req.Concurrency <= 0 {
+		req.Concurrency = min(defaultConcurrency, s.maxConcurrency)
+	}
+	if req.Concurrency > s.maxConcurrency {
+		return nil, fmt.Errorf("concurrency is more than %d", s.maxConcurrency)
+	}
+
+	opts := runner.Options{
+		Targets:       req.Targets,
+		Ports:         req.Ports,
+		Timeout:       time.Duration(req.Timeout),
+		BannerTimeout: time.Duration(req.Banner),
+		Concurrency:   req.Concurrency,
+		Metrics:       s.metrics,
+		MaxProbes:     s.maxProbes,
+	}
+	if s.newScanner != nil {
+		opts.Scanner = s.newScanner(*req)
+	}
+	return runner.New(opts)
+}
+
+// job returns the job named in the request's path, writing a 404 if there
+// is none.
+func (s *Server) job(w http.ResponseWriter, r *http.Request) *Job {
+	s.mu.Lock()
+	job := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+	if job == nil {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+	}
+	return job
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid job: %w", err))
+		return
+	}
+
+	scan, err := s.parseRequest(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job := newJob(newID(), req, scan, s.ctx)
+	s.mu.Lock()
+	select {
+	case s.queue <- job:
+		s.jobs[job.id] = job
+		s.order = append(s.order, job.id)
+		s.mu.Unlock()
+	default:
+		s.mu.Unlock()
+		job.cancel()
+		w.Header().Set("Retry-After", "30")
+		writeError(w, http.StatusTooManyRequests, errors.New("job queue is full"))
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.id)
+	writeJSON(w, http.StatusAccepted, job.view())
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	views := make([]jobView, 0, len(s.order))
+	for _, id := range s.order {
+		views = append(views, s.jobs[id].view())
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if job := s.job(w, r); job != nil {
+		writeJSON(w, http.StatusOK, job.view())
+	}
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	job := s.job(w, r)
+	if job == nil {
+		return
+	}
+	results, _, _ := job.since(0)
+	if results == nil {
+		results = []output.Result{}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	job := s.job(w, r)
+	if job == nil {
+		return
+	}
+	job.stop()
+	writeJSON(w, http.StatusAccepted, job.view())
+}
+
+// handleEvents streams a job's results as Server-Sent Events. Each result is
+// a "result" event whose ID is its position, so a client that reconnects
+// with Last-Event-ID carries on where it left off. An "end" event with the
+// job's final state is sent once the job has finished.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	job := s.job(w, r)
+	if job == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	next := 0
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		if _, err := fmt.Sscanf(id, "%d", &next); err != nil || next < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid Last-Event-ID"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		results, status, changed := job.since(next)
+		for _, result := range results {
+			data, err := json.Marshal(result)
+			if err != nil {
+				return
+			}
+			next++
+			fmt.Fprintf(w, "id: %d\nevent: result\ndata: %s\n\n", next, data)
+		}
+
+		if len(results) > 0 {
+			flusher.Flush()
+			continue
+		}
+		if status.finished() {
+			data, _ := json.Marshal(job.view())
+			fmt.Fprintf(w, "event: end\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// newID returns a random job ID.
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error as a JSON response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
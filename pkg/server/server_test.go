@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner reports even ports open and odd ports closed. Scans block
+// until release is closed, if it is set.
+type fakeScanner struct {
+	release chan struct{}
+}
+
+func (f *fakeScanner) Scan(p scanner.Port) scanner.Port {
+	if f.release != nil {
+		<-f.release
+	}
+	p.Status = scanner.Closed
+	if p.Port%2 == 0 {
+		p.Status = scanner.Open
+	}
+	return p
+}
+
+func newTestServer(t *testing.T, maxRunning, maxQueued int, fake *fakeScanner) (*Server, *httptest.Server) {
+	s := New(maxRunning, maxQueued)
+	s.newScanner = func(JobRequest) scanner.Scanner { return fake }
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	return s, ts
+}
+
+func submit(t *testing.T, ts *httptest.Server, body string) (*http.Response, jobView) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/jobs", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var view jobView
+	if resp.StatusCode == http.StatusAccepted {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&view))
+	}
+	return resp, view
+}
+
+func get(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func waitFor(t *testing.T, ts *httptest.Server, id string, status JobStatus) jobView {
+	t.Helper()
+	var view jobView
+	require.Eventually(t, func() bool {
+		get(t, ts.URL+"/jobs/"+id, &view)
+		return view.Status == status
+	}, 5*time.Second, time.Millisecond)
+	return view
+}
+
+func TestServer_Job(t *testing.T) {
+	_, ts := newTestServer(t, 1, 1, &fakeScanner{})
+
+	resp, job := submit(t, ts, `{"targets": ["10.0.0.0/30"], "ports": "21-24", "timeout": "500ms"}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "/jobs/"+job.ID, resp.Header.Get("Location"))
+	assert.Equal(t, Duration(500*time.Millisecond), job.Request.Timeout)
+	assert.Equal(t, defaultConcurrency, job.Request.Concurrency)
+
+	view := waitFor(t, ts, job.ID, Done)
+	assert.Equal(t, 8, view.Progress.Total)
+	assert.Equal(t, 8, view.Progress.Completed)
+	assert.Equal(t, 4, view.Progress.Open)
+	assert.NotNil(t, view.Finished)
+
+	var results []map[string]any
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/jobs/"+job.ID+"/results", &results))
+	assert.Len(t, results, 8)
+
+	var jobs []jobView
+	get(t, ts.URL+"/jobs", &jobs)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, job.ID, jobs[0].ID)
+}
+
+func TestServer_Events(t *testing.T) {
+	fake := &fakeScanner{release: make(chan struct{})}
+	_, ts := newTestServer(t, 1, 1, fake)
+	_, job := submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "80-82", "concurrency": 1}`)
+
+	resp, err := http.Get(ts.URL + "/jobs/" + job.ID + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	close(fake.release)
+
+	var events, ids []string
+	var last string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, event)
+		}
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			last = data
+		}
+	}
+
+	assert.Equal(t, []string{"result", "result", "result", "end"}, events)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	var end jobView
+	require.NoError(t, json.Unmarshal([]byte(last), &end))
+	assert.Equal(t, Done, end.Status)
+
+	// Reconnecting with Last-Event-ID skips results already received.
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/jobs/"+job.ID+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body := new(strings.Builder)
+	_, err = bufio.NewReader(resp.Body).WriteTo(body)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(body.String(), "event: result"))
+	assert.Contains(t, body.String(), "id: 3\n")
+}
+
+func TestServer_Cancel(t *testing.T) {
+	fake := &fakeScanner{release: make(chan struct{})}
+	_, ts := newTestServer(t, 1, 1, fake)
+
+	_, running := submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "1-100", "concurrency": 1}`)
+	waitFor(t, ts, running.ID, Running)
+	_, queued := submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "80"}`)
+
+	for _, id := range []string{queued.ID, running.ID} {
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/jobs/"+id, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+
+	waitFor(t, ts, queued.ID, Cancelled)
+	close(fake.release)
+	view := waitFor(t, ts, running.ID, Cancelled)
+	assert.Less(t, view.Progress.Completed, 100)
+}
+
+func TestServer_QueueFull(t *testing.T) {
+	fake := &fakeScanner{release: make(chan struct{})}
+	_, ts := newTestServer(t, 1, 1, fake)
+	defer close(fake.release)
+
+	_, running := submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "80"}`)
+	waitFor(t, ts, running.ID, Running)
+
+	resp, _ := submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "80"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, _ = submit(t, ts, `{"targets": ["10.0.0.1/32"], "ports": "80"}`)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+}
+
+func TestServer_InvalidJobs(t *testing.T) {
+	s, ts := newTestServer(t, 1, 1, &fakeScanner{})
+	s.SetMaxProbes(100)
+
+	testCases := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{"not json", `targets`, "invalid job"},
+		{"unknown field", `{"targets": ["10.0.0.0/24"], "rate": 5}`, `unknown field "rate"`},
+		{"no targets", `{}`, "targets is empty"},
+		{"bad target", `{"targets": ["10.0.0.1"]}`, "invalid target 10.0.0.1"},
+		{"bad ports", `{"targets": ["10.0.0.0/24"], "ports": "http"}`, "invalid port: http"},
+		{"bad timeout", `{"targets": ["10.0.0.0/24"], "timeout": 5}`, "duration must be a string"},
+		{"too big", `{"targets": ["10.0.0.0/24"], "ports": "1-10"}`, "scan probes more than 100 ports"},
+		{"huge range", `{"targets": ["2001:db8::/64"]}`, "scan probes more than 100 ports"},
+		{"too concurrent", `{"targets": ["10.0.0.1/32"], "ports": "80", "concurrency": 3000}`, "concurrency is more than 2000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/jobs", "application/json", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Contains(t, body["error"], tc.err)
+		})
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/jobs/missing", nil))
+}
@@ -0,0 +1,109 @@
+package services
+
+import "slices"
+
+// wellKnown maps common TCP ports to their IANA service names.
+var wellKnown = map[int]string{
+	7:     "echo",
+	9:     "discard",
+	13:    "daytime",
+	20:    "ftp-data",
+	21:    "ftp",
+	22:    "ssh",
+	23:    "telnet",
+	25:    "smtp",
+	37:    "time",
+	53:    "domain",
+	79:    "finger",
+	80:    "http",
+	81:    "hosts2-ns",
+	88:    "kerberos-sec",
+	106:   "pop3pw",
+	110:   "pop3",
+	111:   "rpcbind",
+	113:   "ident",
+	119:   "nntp",
+	123:   "ntp",
+	135:   "msrpc",
+	139:   "netbios-ssn",
+	143:   "imap",
+	161:   "snmp",
+	179:   "bgp",
+	389:   "ldap",
+	427:   "svrloc",
+	443:   "https",
+	445:   "microsoft-ds",
+	465:   "smtps",
+	513:   "login",
+	514:   "shell",
+	515:   "printer",
+	543:   "klogin",
+	544:   "kshell",
+	548:   "afp",
+	554:   "rtsp",
+	587:   "submission",
+	631:   "ipp",
+	636:   "ldaps",
+	873:   "rsync",
+	990:   "ftps",
+	993:   "imaps",
+	995:   "pop3s",
+	1080:  "socks",
+	1433:  "ms-sql-s",
+	1521:  "oracle",
+	1723:  "pptp",
+	1883:  "mqtt",
+	1900:  "upnp",
+	2049:  "nfs",
+	2375:  "docker",
+	2376:  "docker-s",
+	3000:  "ppp",
+	3128:  "squid-http",
+	3306:  "mysql",
+	3389:  "ms-wbt-server",
+	5000:  "upnp",
+	5060:  "sip",
+	5432:  "postgresql",
+	5672:  "amqp",
+	5900:  "vnc",
+	5985:  "wsman",
+	5986:  "wsmans",
+	6379:  "redis",
+	6443:  "sun-sr-https",
+	8000:  "http-alt",
+	8008:  "http",
+	8080:  "http-proxy",
+	8443:  "https-alt",
+	8888:  "sun-answerbook",
+	9000:  "cslistener",
+	9090:  "zeus-admin",
+	9100:  "jetdirect",
+	9200:  "wap-wsp",
+	11211: "memcache",
+	27017: "mongod",
+}
+
+// Lookup returns the well-known service name for a TCP port, or an empty
+// string if the port has no common assignment.
+func Lookup(port int) string {
+	return wellKnown[port]
+}
+
+// topPorts are the 100 TCP ports most often found open, according to nmap's
+// port frequency data, in ascending order.
+var topPorts = []int{
+	7, 9, 13, 21, 22, 23, 25, 26, 37, 53, 79, 80, 81, 88, 106, 110, 111, 113,
+	119, 135, 139, 143, 144, 179, 199, 389, 427, 443, 444, 445, 465, 513, 514,
+	515, 543, 544, 548, 554, 587, 631, 646, 873, 990, 993, 995, 1025, 1026,
+	1027, 1028, 1029, 1110, 1433, 1720, 1723, 1755, 1900, 2000, 2001, 2049,
+	2121, 2717, 3000, 3128, 3306, 3389, 3986, 4899, 5000, 5009, 5051, 5060,
+	5101, 5190, 5357, 5432, 5631, 5666, 5800, 5900, 6000, 6001, 6646, 7070,
+	8000, 8008, 8009, 8080, 8081, 8443, 8888, 9100, 9999, 10000, 32768, 49152,
+	49153, 49154, 49155, 49156, 49157,
+}
+
+// TopPorts returns the 100 TCP ports most often found open, in ascending
+// order.
+func TopPorts() []int {
+	return slices.Clone(topPorts)
+}
@@ -0,0 +1,40 @@
+package services
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		port int
+		want string
+	}{
+		{port: 22, want: "ssh"},
+		{port: 80, want: "http"},
+		{port: 443, want: "https"},
+		{port: 3389, want: "ms-wbt-server"},
+		{port: 1, want: ""},
+		{port: 65000, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Lookup(tt.port); got != tt.want {
+			t.Errorf("Lookup(%d) = %q, want %q", tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestTopPorts(t *testing.T) {
+	ports := TopPorts()
+	if len(ports) != 100 {
+		t.Fatalf("expected 100 ports, got %d", len(ports))
+	}
+	for i := 1; i < len(ports); i++ {
+		if ports[i] <= ports[i-1] {
+			t.Errorf("expected ascending ports, got %d after %d", ports[i], ports[i-1])
+		}
+	}
+
+	ports[0] = 0
+	if TopPorts()[0] != 7 {
+		t.Error("expected TopPorts to return a copy")
+	}
+}
@@ -0,0 +1,158 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+const (
+	// maxLatencySamples bounds the memory used to estimate latency
+	// percentiles. Beyond this a uniform random sample is kept.
+	maxLatencySamples = 10000
+	// topPortCount is how many ports are listed in Summary.TopPorts.
+	topPortCount = 10
+)
+
+// PortCount is the number of hosts a port was open on.
+type PortCount struct {
+	Port  int `json:"port"`
+	Count int `json:"count"`
+}
+
+// Summary describes the results of a finished scan.
+type Summary struct {
+	Hosts         int
+	HostsWithOpen int
+	Open          int
+	Closed        int
+	Timeout       int
+	TopPorts      []PortCount
+	Elapsed       time.Duration
+	// LatencyP50 and LatencyP95 are percentiles of the connect latency of
+	// ports that answered. Timed out probes are not included.
+	LatencyP50 time.Duration
+	LatencyP95 time.Duration
+}
+
+// Collector aggregates scan results into a Summary. It is safe for
+// concurrent use.
+type Collector struct {
+	mu        sync.Mutex
+	hosts     map[string]bool
+	counts    map[scanner.Status]int
+	openPorts map[int]int
+	latencies []time.Duration
+	answered  int
+	rand      *rand.Rand
+}
+
+// NewCollector creates a new Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		hosts:     make(map[string]bool),
+		counts:    make(map[scanner.Status]int),
+		openPorts: make(map[int]int),
+		rand:      rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add records a single scan result.
+func (c *Collector) Add(p scanner.Port) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[p.Status]++
+	c.hosts[p.Host] = c.hosts[p.Host] || p.Status == scanner.Open
+	if p.Status == scanner.Open {
+		c.openPorts[p.Port]++
+	}
+
+	if p.Status == scanner.Timeout {
+		return
+	}
+	c.answered++
+	if len(c.latencies) < maxLatencySamples {
+		c.latencies = append(c.latencies, p.Latency)
+	} else if i := c.rand.Intn(c.answered); i < maxLatencySamples {
+		c.latencies[i] = p.Latency
+	}
+}
+
+// Summary summarises the results recorded so far.
+func (c *Collector) Summary(elapsed time.Duration) *Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := &Summary{
+		Hosts:   len(c.hosts),
+		Open:    c.counts[scanner.Open],
+		Closed:  c.counts[scanner.Closed],
+		Timeout: c.counts[scanner.Timeout],
+		Elapsed: elapsed,
+	}
+	for _, open := range c.hosts {
+		if open {
+			s.HostsWithOpen++
+		}
+	}
+
+	for port, count := range c.openPorts {
+		s.TopPorts = append(s.TopPorts, PortCount{Port: port, Count: count})
+	}
+	slices.SortFunc(s.TopPorts, func(a, b PortCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Port, b.Port)
+	})
+	if len(s.TopPorts) > topPortCount {
+		s.TopPorts = s.TopPorts[:topPortCount]
+	}
+
+	latencies := slices.Clone(c.latencies)
+	slices.Sort(latencies)
+	s.LatencyP50 = percentile(latencies, 50)
+	s.LatencyP95 = percentile(latencies, 95)
+
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// Write writes the summary in a human readable form.
+func (s *Summary) Write(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("Summary\n")
+	fmt.Fprintf(&b, "  Hosts scanned:          %d\n", s.Hosts)
+	fmt.Fprintf(&b, "  Hosts with open ports:  %d\n", s.HostsWithOpen)
+	fmt.Fprintf(&b, "  Ports:                  %d open, %d closed, %d timed out\n", s.Open, s.Closed, s.Timeout)
+	if len(s.TopPorts) > 0 {
+		top := make([]string, len(s.TopPorts))
+		for i, pc := range s.TopPorts {
+			top[i] = fmt.Sprintf("%d (%d)", pc.Port, pc.Count)
+		}
+		fmt.Fprintf(&b, "  Most common open ports: %s\n", strings.Join(top, ", "))
+	}
+	fmt.Fprintf(&b, "  Elapsed:                %s\n", s.Elapsed.Round(time.Millisecond))
+	if s.Open+s.Closed > 0 {
+		fmt.Fprintf(&b, "  Connect latency:        p50 %s, p95 %s\n", s.LatencyP50.Round(time.Microsecond), s.LatencyP95.Round(time.Microsecond))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+func TestCollector_Summary(t *testing.T) {
+	c := NewCollector()
+	c.Add(scanner.Port{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Latency: 1 * time.Millisecond})
+	c.Add(scanner.Port{Host: "10.0.0.1", Port: 80, Status: scanner.Open, Latency: 2 * time.Millisecond})
+	c.Add(scanner.Port{Host: "10.0.0.2", Port: 22, Status: scanner.Open, Latency: 3 * time.Millisecond})
+	c.Add(scanner.Port{Host: "10.0.0.2", Port: 80, Status: scanner.Closed, Latency: 4 * time.Millisecond})
+	c.Add(scanner.Port{Host: "10.0.0.3", Port: 22, Status: scanner.Timeout, Latency: time.Second})
+	c.Add(scanner.Port{Host: "10.0.0.3", Port: 80, Status: scanner.Closed, Latency: 20 * time.Millisecond})
+
+	s := c.Summary(5 * time.Second)
+
+	assert.Equal(t, &Summary{
+		Hosts:         3,
+		HostsWithOpen: 2,
+		Open:          3,
+		Closed:        2,
+		Timeout:       1,
+		TopPorts:      []PortCount{{Port: 22, Count: 2}, {Port: 80, Count: 1}},
+		Elapsed:       5 * time.Second,
+		LatencyP50:    3 * time.Millisecond,
+		LatencyP95:    20 * time.Millisecond,
+	}, s)
+}
+
+func TestCollector_BoundedLatencySamples(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < maxLatencySamples*3; i++ {
+		c.Add(scanner.Port{Host: "10.0.0.1", Port: i, Status: scanner.Closed, Latency: time.Duration(i%100) * time.Millisecond})
+	}
+
+	assert.Len(t, c.latencies, maxLatencySamples)
+
+	s := c.Summary(time.Second)
+	assert.InDelta(t, 50*time.Millisecond, s.LatencyP50, float64(5*time.Millisecond))
+	assert.InDelta(t, 95*time.Millisecond, s.LatencyP95, float64(5*time.Millisecond))
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+
+	values := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), percentile(values, 50))
+	assert.Equal(t, time.Duration(10), percentile(values, 95))
+	assert.Equal(t, time.Duration(1), percentile(values, 0))
+}
+
+func TestSummary_Write(t *testing.T) {
+	s := &Summary{
+		Hosts:         3,
+		HostsWithOpen: 2,
+		Open:          3,
+		Closed:        2,
+		Timeout:       1,
+		TopPorts:      []PortCount{{Port: 22, Count: 2}, {Port: 80, Count: 1}},
+		Elapsed:       5123456 * time.Microsecond,
+		LatencyP50:    3 * time.Millisecond,
+		LatencyP95:    20 * time.Millisecond,
+	}
+
+	writer := &bytes.Buffer{}
+	assert.NoError(t, s.Write(writer))
+
+	expected := "Summary\n" +
+		"  Hosts scanned:          3\n" +
+		"  Hosts with open ports:  2\n" +
+		"  Ports:                  3 open, 2 closed, 1 timed out\n" +
+		"  Most common open ports: 22 (2), 80 (1)\n" +
+		"  Elapsed:                5.123s\n" +
+		"  Connect latency:        p50 3ms, p95 20ms\n"
+	assert.Equal(t, expected, writer.String())
+}
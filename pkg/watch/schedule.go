@@ -0,0 +1,32 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule decides when scans start.
+type Schedule interface {
+	// Next returns the first time a scan should start after t.
+	Next(t time.Time) time.Time
+}
+
+// every is a Schedule that starts scans at a fixed interval.
+type every time.Duration
+
+// Every returns a Schedule that starts a scan interval after the previous
+// one finished.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// ParseCron parses a standard five field cron expression, such as
+// "0 */6 * * *", or a descriptor such as "@daily".
+func ParseCron(spec string) (Schedule, error) {
+	return cron.ParseStandard(spec)
+}
@@ -0,0 +1,196 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/diff"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+)
+
+// EventType is the kind of change an Event describes.
+type EventType string
+
+const (
+	HostAppeared EventType = "host_appeared"
+	HostVanished EventType = "host_vanished"
+	PortOpened   EventType = "port_opened"
+	PortClosed   EventType = "port_closed"
+	PortChanged  EventType = "port_changed"
+)
+
+// Event is a single change found by comparing a scan with the previous one.
+type Event struct {
+	Time   time.Time       `json:"time"`
+	Type   EventType       `json:"type"`
+	Host   string          `json:"host"`
+	Port   int             `json:"port,omitempty"`
+	Before *diff.PortState `json:"before,omitempty"`
+	After  *diff.PortState `json:"after,omitempty"`
+}
+
+// String describes the event in a single line.
+func (e Event) String() string {
+	target := e.Host
+	if e.Port != 0 {
+		target = net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	}
+	line := fmt.Sprintf("%s %s %s", e.Time.Format(time.RFC3339), e.Type, target)
+	if e.After != nil && e.After.Service != "" {
+		line += " " + e.After.Service
+	}
+	return line
+}
+
+// Events lists the changes in a report as events at time t.
+func Events(report *diff.Report, t time.Time) []Event {
+	var events []Event
+	for _, host := range report.NewHosts {
+		events = append(events, Event{Time: t, Type: HostAppeared, Host: host})
+	}
+	for _, host := range report.VanishedHosts {
+		events = append(events, Event{Time: t, Type: HostVanished, Host: host})
+	}
+	changes := func(typ EventType, changes []diff.PortChange) {
+		for _, c := range changes {
+			events = append(events, Event{Time: t, Type: typ, Host: c.Host, Port: c.Port, Before: c.Before, After: c.After})
+		}
+	}
+	changes(PortOpened, report.Opened)
+	changes(PortClosed, report.Closed)
+	changes(PortChanged, report.Changed)
+	return events
+}
+
+// ScanFunc runs a single scan. It should stop early and return the context's
+// error when ctx is done.
+type ScanFunc func(ctx context.Context) ([]output.Result, error)
+
+// Cycle is a single completed scan of a Watcher.
+type Cycle struct {
+	Start   time.Time
+	End     time.Time
+	Results []output.Result
+	// Baseline is set when there was no previous scan to compare with, so
+	// there are no events.
+	Baseline bool
+	Events   []Event
+}
+
+// Watcher repeatedly scans on a schedule and reports what changed between
+// scans.
+type Watcher struct {
+	scan      ScanFunc
+	schedule  Schedule
+	handle    func(Cycle) error
+	stateFile string
+	previous  []output.Result
+	// baseline is set once there is a previous scan to compare with.
+	baseline bool
+}
+
+// NewWatcher creates a new Watcher that runs scan on schedule and passes
+// each completed scan to handle.
+func NewWatcher(scan ScanFunc, schedule Schedule, handle func(Cycle) error) *Watcher {
+	return &Watcher{scan: scan, schedule: schedule, handle: handle}
+}
+
+// SetStateFile keeps the results of the latest scan in a JSON results file,
+// so that a restarted Watcher compares its first scan with the last one
+// before it stopped.
+func (w *Watcher) SetStateFile(path string) {
+	w.stateFile = path
+}
+
+// Run scans immediately and then on the schedule until ctx is done. Scans
+// never overlap: a scheduled time that passes while a scan is running is
+// skipped. A scan that is interrupted by ctx is discarded, since comparing
+// it would report every port it did not reach as closed.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.stateFile != "" {
+		previous, err := output.ReadFile(w.stateFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("loading state: %w", err)
+		}
+		w.previous = previous
+		w.baseline = err == nil
+	}
+
+	for {
+		if err := w.cycle(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		timer := time.NewTimer(time.Until(w.schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// cycle runs a single scan and compares it with the previous one.
+func (w *Watcher) cycle(ctx context.Context) error {
+	c := Cycle{Start: time.Now()}
+	results, err := w.scan(ctx)
+	if err != nil {
+		return err
+	}
+	c.End = time.Now()
+	c.Results = results
+
+	if w.baseline {
+		c.Events = Events(diff.Compare(w.previous, results), c.End)
+	} else {
+		c.Baseline = true
+	}
+	w.previous = results
+	w.baseline = true
+
+	if w.stateFile != "" {
+		if err := saveState(w.stateFile, c); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+	}
+	return w.handle(c)
+}
+
+// saveState writes the results of a scan to path as a JSON results file.
+// The file is replaced atomically, so it is never left half written.
+func saveState(path string, c Cycle) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	writer := output.NewJsonWriter(f)
+	run := &output.ScanRun{Start: c.Start, End: c.End}
+	err = writer.Begin(run)
+	for _, r := range c.Results {
+		if err != nil {
+			break
+		}
+		err = writer.WriteResult(r)
+	}
+	if err == nil {
+		err = writer.End(run)
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,130 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scans returns a ScanFunc that returns each of the given scans in turn,
+// and cancels the watch once they have all been used.
+func scans(cancel context.CancelFunc, all ...[]output.Result) ScanFunc {
+	i := 0
+	return func(ctx context.Context) ([]output.Result, error) {
+		if i == len(all) {
+			cancel()
+			return nil, ctx.Err()
+		}
+		i++
+		return all[i-1], nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	first := []output.Result{
+		{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Service: "ssh"},
+		{Host: "10.0.0.1", Port: 80, Status: scanner.Closed},
+	}
+	second := []output.Result{
+		{Host: "10.0.0.1", Port: 22, Status: scanner.Open, Service: "ssh"},
+		{Host: "10.0.0.1", Port: 80, Status: scanner.Open, Service: "http"},
+		{Host: "10.0.0.2", Port: 22, Status: scanner.Closed},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cycles []Cycle
+	watcher := NewWatcher(scans(cancel, first, second, second), Every(time.Millisecond), func(c Cycle) error {
+		cycles = append(cycles, c)
+		return nil
+	})
+	require.NoError(t, watcher.Run(ctx))
+
+	require.Len(t, cycles, 3)
+	assert.True(t, cycles[0].Baseline)
+	assert.Empty(t, cycles[0].Events)
+
+	assert.False(t, cycles[1].Baseline)
+	require.Len(t, cycles[1].Events, 2)
+	assert.Equal(t, HostAppeared, cycles[1].Events[0].Type)
+	assert.Equal(t, "10.0.0.2", cycles[1].Events[0].Host)
+	assert.Equal(t, PortOpened, cycles[1].Events[1].Type)
+	assert.Equal(t, 80, cycles[1].Events[1].Port)
+
+	assert.Empty(t, cycles[2].Events)
+}
+
+func TestWatcher_StateFile(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state.json")
+	before := []output.Result{{Host: "10.0.0.1", Port: 22, Status: scanner.Open}}
+	after := []output.Result{{Host: "10.0.0.1", Port: 22, Status: scanner.Closed}}
+
+	run := func(results []output.Result) Cycle {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var cycle Cycle
+		watcher := NewWatcher(scans(cancel, results), Every(time.Millisecond), func(c Cycle) error {
+			cycle = c
+			return nil
+		})
+		watcher.SetStateFile(state)
+		require.NoError(t, watcher.Run(ctx))
+		return cycle
+	}
+
+	assert.True(t, run(before).Baseline)
+
+	cycle := run(after)
+	assert.False(t, cycle.Baseline)
+	require.Len(t, cycle.Events, 1)
+	assert.Equal(t, PortClosed, cycle.Events[0].Type)
+
+	saved, err := output.ReadFile(state)
+	require.NoError(t, err)
+	assert.Equal(t, scanner.Closed, saved[0].Status)
+}
+
+func TestWatcher_Errors(t *testing.T) {
+	scanErr := errors.New("scan failed")
+	watcher := NewWatcher(func(ctx context.Context) ([]output.Result, error) {
+		return nil, scanErr
+	}, Every(time.Hour), func(c Cycle) error { return nil })
+	assert.ErrorIs(t, watcher.Run(context.Background()), scanErr)
+
+	handleErr := errors.New("handler failed")
+	watcher = NewWatcher(func(ctx context.Context) ([]output.Result, error) {
+		return nil, nil
+	}, Every(time.Hour), func(c Cycle) error { return handleErr })
+	assert.ErrorIs(t, watcher.Run(context.Background()), handleErr)
+}
+
+func TestEvent_String(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := Event{Time: at, Type: PortOpened, Host: "2001:db8::1", Port: 443}
+	assert.Equal(t, "2024-01-01T12:00:00Z port_opened [2001:db8::1]:443", e.String())
+
+	e = Event{Time: at, Type: HostVanished, Host: "10.0.0.1"}
+	assert.Equal(t, "2024-01-01T12:00:00Z host_vanished 10.0.0.1", e.String())
+}
+
+func TestSchedule(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 34, 0, 0, time.UTC)
+	assert.Equal(t, at.Add(time.Minute), Every(time.Minute).Next(at))
+
+	schedule, err := ParseCron("0 */6 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC), schedule.Next(at))
+
+	_, err = ParseCron("every day")
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package scan_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/scan"
+)
+
+func Example() {
+	// Listen on a port so that there is something to find.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	scanner, err := scan.New(scan.WithTimeout(time.Second), scan.WithConcurrency(100))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results, err := scanner.Run(context.Background(), []string{"127.0.0.1"}, []int{port})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for r := range results {
+		fmt.Println(r.Host, r.Status)
+	}
+	// Output: 127.0.0.1 Open
+}
+
+func ExampleWithProber() {
+	// A prober that finds SSH open everywhere, instead of connecting.
+	prober := scan.ProberFunc(func(p scan.Port) scan.Port {
+		p.Status = scan.Closed
+		if p.Port == 22 {
+			p.Status = scan.Open
+		}
+		return p
+	})
+
+	scanner, err := scan.New(scan.WithProber(prober))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results, err := scanner.Run(context.Background(), []string{"10.0.0.0/30"}, []int{22, 80})
+	if err != nil {
+		log.Fatal(err)
+	}
+	var open []string
+	for r := range results {
+		if r.Status == scan.Open {
+			open = append(open, fmt.Sprintf("%s:%d %s", r.Host, r.Port, r.Service))
+		}
+	}
+	slices.Sort(open)
+	fmt.Println(open)
+	// Output: [10.0.0.1:22 ssh 10.0.0.2:22 ssh]
+}
+
+func ExampleOnProgress() {
+	prober := scan.ProberFunc(func(p scan.Port) scan.Port {
+		p.Status = scan.Closed
+		return p
+	})
+
+	var last scan.Progress
+	scanner, err := scan.New(
+		scan.WithProber(prober),
+		scan.WithConcurrency(1),
+		scan.OnProgress(func(p scan.Progress) { last = p }),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results, err := scanner.Run(context.Background(), []string{"192.168.1.0/29"}, []int{80, 443})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for range results {
+	}
+	fmt.Printf("%d of %d ports scanned, %d open\n", last.Completed, last.Total, last.Open)
+	// Output: 12 of 12 ports scanned, 0 open
+}
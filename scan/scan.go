@@ -0,0 +1,232 @@
+// Package scan scans networks for open TCP ports, for programs that embed
+// the scanner instead of running the network-scanner command.
+//
+// Its API, and that of the packages under pkg/, follows semantic
+// versioning: exported names are not removed or changed incompatibly
+// without a new major version.
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theryanhowell/network-scanner/pkg/iputil"
+	"github.com/theryanhowell/network-scanner/pkg/output"
+	"github.com/theryanhowell/network-scanner/pkg/runner"
+	"github.com/theryanhowell/network-scanner/pkg/scanner"
+)
+
+// Result is the result of probing a single port.
+type Result = output.Result
+
+// Status is the state a probed port was found in.
+type Status = scanner.Status
+
+// The states a port can be found in.
+const (
+	Open    = scanner.Open
+	Closed  = scanner.Closed
+	Timeout = scanner.Timeout
+)
+
+// Port is a port on a host, as given to and returned by a Prober.
+type Port = scanner.Port
+
+// Progress is a snapshot of how far a scan has got.
+type Progress = scanner.ProgressSnapshot
+
+// Prober probes a single port. Scan is given the port's host and number
+// and returns it with Status, Latency and, if read, Banner set. It must be
+// safe for concurrent use.
+type Prober = scanner.Scanner
+
+// ProberFunc adapts a function to a Prober.
+type ProberFunc func(Port) Port
+
+// Scan calls f.
+func (f ProberFunc) Scan(p Port) Port {
+	return f(p)
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner) error
+
+// WithTimeout sets how long to wait for each connection. The default is
+// three seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Scanner) error {
+		if d <= 0 {
+			return errors.New("timeout must be greater than zero")
+		}
+		s.timeout = d
+		return nil
+	}
+}
+
+// WithBannerTimeout reads a banner from each open port, waiting up to d
+// for one. Banners are not read by default.
+func WithBannerTimeout(d time.Duration) Option {
+	return func(s *Scanner) error {
+		if d < 0 {
+			return errors.New("banner timeout must not be negative")
+		}
+		s.bannerTimeout = d
+		return nil
+	}
+}
+
+// WithConcurrency limits the number of ports probed at once. By default
+// every port is probed at once.
+func WithConcurrency(n int) Option {
+	return func(s *Scanner) error {
+		if n < 0 {
+			return errors.New("concurrency must not be negative")
+		}
+		s.concurrency = n
+		return nil
+	}
+}
+
+// WithRate limits the number of ports started each second. There is no
+// limit by default.
+func WithRate(perSecond float64) Option {
+	return func(s *Scanner) error {
+		if perSecond < 0 {
+			return errors.New("rate must not be negative")
+		}
+		s.rate = perSecond
+		return nil
+	}
+}
+
+// WithRetries probes a port that timed out up to n more times.
+func WithRetries(n int) Option {
+	return func(s *Scanner) error {
+		if n < 0 {
+			return errors.New("retries must not be negative")
+		}
+		s.retries = n
+		return nil
+	}
+}
+
+// WithProber probes ports with p instead of making a TCP connection to
+// each. The timeouts set by WithTimeout and WithBannerTimeout are then up
+// to p.
+func WithProber(p Prober) Option {
+	return func(s *Scanner) error {
+		if p == nil {
+			return errors.New("prober is nil")
+		}
+		s.prober = p
+		return nil
+	}
+}
+
+// OnResult calls fn with each result before it is sent on the channel
+// returned by Run.
+func OnResult(fn func(Result)) Option {
+	return func(s *Scanner) error {
+		s.onResult = append(s.onResult, fn)
+		return nil
+	}
+}
+
+// OnProgress calls fn with the progress of the scan after each result.
+func OnProgress(fn func(Progress)) Option {
+	return func(s *Scanner) error {
+		s.onProgress = append(s.onProgress, fn)
+		return nil
+	}
+}
+
+// Scanner scans networks with a fixed set of options. It can run several
+// scans, one after another or at once.
+type Scanner struct {
+	timeout       time.Duration
+	bannerTimeout time.Duration
+	concurrency   int
+	rate          float64
+	retries       int
+	prober        Prober
+	onResult      []func(Result)
+	onProgress    []func(Progress)
+}
+
+// New creates a Scanner with opts applied in order.
+func New(opts ...Option) (*Scanner, error) {
+	s := &Scanner{timeout: runner.DefaultTimeout}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Run starts scanning ports on every host in targets, which are CIDR ranges
+// or single addresses. If ports is empty, ports 1 to 1024 are scanned.
+//
+// Results are sent on the returned channel, which is closed once the scan
+// has finished. The channel must be read until it is closed. Once ctx is
+// done no more ports are started, and the results of those in progress are
+// still sent.
+func (s *Scanner) Run(ctx context.Context, targets []string, ports []int) (<-chan Result, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no targets")
+	}
+
+	ranges := make([]string, len(targets))
+	for i, target := range targets {
+		prefix, err := iputil.ParsePrefix(target)
+		if err != nil {
+			return nil, err
+		}
+		ranges[i] = prefix.String()
+	}
+
+	spec := make([]string, len(ports))
+	for i, port := range ports {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port: %d", port)
+		}
+		spec[i] = strconv.Itoa(port)
+	}
+
+	r, err := runner.New(runner.Options{
+		Targets:       ranges,
+		Ports:         strings.Join(spec, ","),
+		Timeout:       s.timeout,
+		BannerTimeout: s.bannerTimeout,
+		Concurrency:   s.concurrency,
+		Rate:          s.rate,
+		Retries:       s.retries,
+		Scanner:       s.prober,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+		for p := range r.Results(ctx) {
+			result := output.NewResult(p)
+			for _, fn := range s.onResult {
+				fn(result)
+			}
+			if len(s.onProgress) > 0 {
+				progress := r.Progress().Snapshot()
+				for _, fn := range s.onProgress {
+					fn(progress)
+				}
+			}
+			results <- result
+		}
+	}()
+	return results, nil
+}
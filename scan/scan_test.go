@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedProber finds every port closed.
+var closedProber = ProberFunc(func(p Port) Port {
+	p.Status = Closed
+	return p
+})
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		opt Option
+		err string
+	}{
+		{WithTimeout(0), "timeout must be greater than zero"},
+		{WithBannerTimeout(-time.Second), "banner timeout must not be negative"},
+		{WithConcurrency(-1), "concurrency must not be negative"},
+		{WithRate(-1), "rate must not be negative"},
+		{WithRetries(-1), "retries must not be negative"},
+		{WithProber(nil), "prober is nil"},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.opt)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestScanner_Run_InvalidArguments(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	_, err = s.Run(context.Background(), nil, []int{80})
+	assert.EqualError(t, err, "no targets")
+	_, err = s.Run(context.Background(), []string{"10.0.0"}, []int{80})
+	assert.EqualError(t, err, "invalid address: 10.0.0")
+	_, err = s.Run(context.Background(), []string{"10.0.0.1"}, []int{0})
+	assert.EqualError(t, err, "invalid port: 0")
+}
+
+func TestScanner_Run(t *testing.T) {
+	var mu sync.Mutex
+	var probed []string
+	prober := ProberFunc(func(p Port) Port {
+		mu.Lock()
+		probed = append(probed, p.String())
+		mu.Unlock()
+		p.Status = Closed
+		return p
+	})
+
+	var seen []Result
+	s, err := New(WithProber(prober), WithConcurrency(1), OnResult(func(r Result) {
+		seen = append(seen, r)
+	}))
+	require.NoError(t, err)
+
+	results, err := s.Run(context.Background(), []string{"10.0.0.1", "10.0.1.0/30"}, []int{22, 80})
+	require.NoError(t, err)
+	var got []Result
+	for r := range results {
+		got = append(got, r)
+	}
+
+	assert.Equal(t, []string{
+		"10.0.0.1:22", "10.0.0.1:80",
+		"10.0.1.1:22", "10.0.1.1:80",
+		"10.0.1.2:22", "10.0.1.2:80",
+	}, probed)
+	assert.Equal(t, got, seen)
+	assert.Equal(t, "ssh", got[0].Service)
+}
+
+func TestScanner_Run_DefaultPorts(t *testing.T) {
+	s, err := New(WithProber(closedProber))
+	require.NoError(t, err)
+
+	results, err := s.Run(context.Background(), []string{"10.0.0.1"}, nil)
+	require.NoError(t, err)
+	count := 0
+	for range results {
+		count++
+	}
+	assert.Equal(t, 1024, count)
+}
+
+func TestScanner_Run_Cancel(t *testing.T) {
+	s, err := New(WithProber(closedProber), WithConcurrency(1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := s.Run(ctx, []string{"10.0.0.0/24"}, []int{22})
+	require.NoError(t, err)
+
+	count := 0
+	for range results {
+		count++
+		cancel()
+	}
+	assert.Less(t, count, 254)
+}